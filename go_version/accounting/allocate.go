@@ -0,0 +1,57 @@
+package accounting
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+)
+
+// Allocate splits the amount proportionally to the weights without losing any minor unit
+// the remainder is distributed one unit at a time to the largest fractional parts, ties going to the first ones,
+// so the result is deterministic and always sums to the original amount
+func (m Money) Allocate(weights []int64) ([]Money, error) {
+	total := new(big.Int)
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errors.New("allocation weights must not be negative")
+		}
+		total.Add(total, big.NewInt(w))
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("allocation weights must not all be zero")
+	}
+
+	amount := big.NewInt(m.minor)
+	sign := int64(1)
+	if amount.Sign() < 0 {
+		amount.Neg(amount)
+		sign = -1
+	}
+
+	parts := make([]Money, len(weights))
+	rems := make([]*big.Int, len(weights))
+	left := m.Abs().minor
+	for i, w := range weights {
+		q, r := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(w)), total, new(big.Int))
+		parts[i] = Money{minor: q.Int64(), scale: m.scale}
+		rems[i] = r
+		left -= q.Int64()
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return rems[b].Cmp(rems[a])
+	})
+	for i := 0; left > 0; i++ {
+		parts[order[i]].minor++
+		left--
+	}
+
+	for i := range parts {
+		parts[i].minor *= sign
+	}
+	return parts, nil
+}
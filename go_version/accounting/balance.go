@@ -2,8 +2,9 @@ package accounting
 
 // Balance holds the current amount for a person
 type Balance struct {
-	Name   string  `json:"name"`
-	Amount float64 `json:"amount"`
+	Name     string `json:"name"`
+	Amount   Money  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
 }
 
 // Balances type alias for Balance slice
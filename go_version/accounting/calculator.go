@@ -2,35 +2,51 @@ package accounting
 
 import (
 	"cmp"
-	"math"
+	"fmt"
 	"slices"
 )
 
 // calculateBalance calculates the final balance for each person involved in the transactions
-func calculateBalance(transactions Transactions) Balances {
-	b := make(map[string]float64, len(transactions))
+// it fails with ErrAmountTooLarge when a balance is above MaxAmount
+func calculateBalance(transactions Transactions) (Balances, error) {
+	b := make(map[string]Money, len(transactions))
+	var err error
 	for _, t := range transactions {
-		if t.From != t.To {
-			b[t.From] += t.Amount
-			b[t.To] -= t.Amount
-		} else {
-			// if self just record it as 0, for person to be accounted in balance
-			b[t.To] += 0.0
+		// with several payers every contribution is a transaction of its own
+		for _, p := range t.Payments() {
+			if p.From == p.To {
+				// if self just record it as 0, for person to be accounted in balance
+				b[p.To] = b[p.To].Add(p.Amount.Zero())
+				continue
+			}
+			if b[p.From], err = b[p.From].CheckedAdd(p.Amount); err != nil {
+				return nil, err
+			}
+			if b[p.To], err = b[p.To].CheckedSub(p.Amount); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	finalB := make(Balances, 0, len(b))
 	for n, a := range b {
+		if err := checkRange(a); err != nil {
+			return nil, fmt.Errorf("balance of %s: %w", n, err)
+		}
 		finalB = append(finalB, Balance{Name: n, Amount: a})
 	}
-	return finalB
+	// map iteration order is random, sorting keeps responses stable
+	slices.SortFunc(finalB, func(b1 Balance, b2 Balance) int {
+		return cmp.Compare(b1.Name, b2.Name)
+	})
+	return finalB, nil
 }
 
 // minimizeTransactions finds the minimum number of transactions to balance to 0 the amount of each person
 func minimizeTransactions(balances Balances) Statement {
 	finalBalances := append(Balances{}, balances...)
 	slices.SortFunc(finalBalances, func(b1 Balance, b2 Balance) int {
-		return cmp.Compare(b1.Amount, b2.Amount)
+		return b1.Amount.Cmp(b2.Amount)
 	})
 
 	finalTransactions := make(Transactions, 0, len(balances))
@@ -40,24 +56,29 @@ func minimizeTransactions(balances Balances) Statement {
 		neg := finalBalances[negIdx]
 		pos := finalBalances[posIdx]
 
-		diff := pos.Amount - math.Abs(neg.Amount)
+		// nothing left to settle, remaining balances are all debts or all credits
+		if neg.Amount.Sign() >= 0 || pos.Amount.Sign() <= 0 {
+			break
+		}
+
+		diff := pos.Amount.Sub(neg.Amount.Abs())
 
 		// tAmount is the lowest between two parties from transaction
-		tAmount := math.Min(math.Abs(neg.Amount), pos.Amount)
+		tAmount := MinMoney(neg.Amount.Abs(), pos.Amount)
 		finalTransactions = append(finalTransactions, Transaction{From: neg.Name, To: pos.Name, Amount: tAmount})
 
-		neg.Amount = math.Min(0.0, diff) // if < 0 still has debt
-		pos.Amount = math.Max(0.0, diff) // if > 0 still has to receive
+		neg.Amount = MinMoney(diff.Zero(), diff) // if < 0 still has debt
+		pos.Amount = MaxMoney(diff.Zero(), diff) // if > 0 still has to receive
 
 		finalBalances[negIdx], finalBalances[posIdx] = neg, pos
 
-		if neg.Amount == 0.0 {
+		if neg.Amount.IsZero() {
 			negIdx++
 		}
 
-		if pos.Amount == 0.0 {
+		if pos.Amount.IsZero() {
 			posIdx--
 		}
 	}
-	return Statement{finalBalances, finalTransactions}
+	return Statement{UpdatedBalances: finalBalances, Transactions: finalTransactions}
 }
@@ -16,52 +16,77 @@ func Test_Calculate_Balance(t *testing.T) {
 		{
 			name: "given example",
 			input: Transactions{
-				{"A", "B", 40.0},
-				{"B", "C", 40.0},
-				{"C", "A", 10.0},
+				{From: "A", To: "B", Amount: money("40.0")},
+				{From: "B", To: "C", Amount: money("40.0")},
+				{From: "C", To: "A", Amount: money("10.0")},
 			},
 			expected: Balances{
-				{"A", 30.0},
-				{"B", 0.0},
-				{"C", -30.0},
+				{Name: "A", Amount: money("30.0")},
+				{Name: "B", Amount: money("0.0")},
+				{Name: "C", Amount: money("-30.0")},
 			},
 		},
 		{
 			name: "when more transactions",
 			input: Transactions{
-				{"A", "B", 50.0},
-				{"B", "C", 40.0},
-				{"C", "B", 10.0},
-				{"D", "A", 20.0},
-				{"A", "C", 30.0},
-				{"E", "A", 5.0},
+				{From: "A", To: "B", Amount: money("50.0")},
+				{From: "B", To: "C", Amount: money("40.0")},
+				{From: "C", To: "B", Amount: money("10.0")},
+				{From: "D", To: "A", Amount: money("20.0")},
+				{From: "A", To: "C", Amount: money("30.0")},
+				{From: "E", To: "A", Amount: money("5.0")},
 			},
 			expected: Balances{
-				{"A", 55.0},
-				{"B", -20.0},
-				{"C", -60.0},
-				{"D", 20.0},
-				{"E", 5.0},
+				{Name: "A", Amount: money("55.0")},
+				{Name: "B", Amount: money("-20.0")},
+				{Name: "C", Amount: money("-60.0")},
+				{Name: "D", Amount: money("20.0")},
+				{Name: "E", Amount: money("5.0")},
 			},
 		},
 		{
 			name: "when self transaction",
 			input: Transactions{
-				{"A", "B", 50.0},
-				{"B", "B", 50.0},
+				{From: "A", To: "B", Amount: money("50.0")},
+				{From: "B", To: "B", Amount: money("50.0")},
 			},
 			expected: Balances{
-				{"A", 50.0},
-				{"B", -50.0},
+				{Name: "A", Amount: money("50.0")},
+				{Name: "B", Amount: money("-50.0")},
 			},
 		},
 		{
 			name: "when single self transaction",
 			input: Transactions{
-				{"B", "B", 50.0},
+				{From: "B", To: "B", Amount: money("50.0")},
 			},
 			expected: Balances{
-				{"B", 0.0},
+				{Name: "B", Amount: money("0.0")},
+			},
+		},
+		{
+			name: "when decimal amounts",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("0.1")},
+				{From: "A", To: "B", Amount: money("0.2")},
+				{From: "C", To: "A", Amount: money("0.3")},
+			},
+			expected: Balances{
+				{Name: "A", Amount: money("0.0")},
+				{Name: "B", Amount: money("-0.3")},
+				{Name: "C", Amount: money("0.3")},
+			},
+		},
+		{
+			name: "when several payers",
+			input: Transactions{
+				{To: "B", Amount: money("100.0"), Contributors: []Contribution{{Name: "A", Amount: money("60.0")}, {Name: "C", Amount: money("40.0")}}},
+				{To: "A", Amount: money("30.0"), Contributors: []Contribution{{Name: "A", Amount: money("10.0")}, {Name: "B", Amount: money("20.0")}}},
+			},
+			expected: Balances{
+				{Name: "A", Amount: money("40.0")},
+				{Name: "B", Amount: money("-80.0")},
+				{Name: "C", Amount: money("40.0")},
 			},
 		},
 		{
@@ -73,7 +98,10 @@ func Test_Calculate_Balance(t *testing.T) {
 
 	for _, s := range scenarios {
 		t.Run(s.name, func(t *testing.T) {
-			actual := calculateBalance(s.input)
+			actual, err := calculateBalance(s.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
 
 			if !reflect.DeepEqual(
 				sortedBalances(s.expected),
@@ -94,150 +122,183 @@ func Test_Minimize_Transactions(t *testing.T) {
 		{
 			name: "given example",
 			input: Balances{
-				{"A", 30.0},
-				{"B", 0.0},
-				{"C", -30.0},
+				{Name: "A", Amount: money("30.0")},
+				{Name: "B", Amount: money("0.0")},
+				{Name: "C", Amount: money("-30.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 0.0},
-					{"B", 0.0},
-					{"C", 0.0},
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"C", "A", 30.0},
+					{From: "C", To: "A", Amount: money("30.0")},
 				},
 			},
 		},
 		{
 			name: "when more debts than credits",
 			input: Balances{
-				{"A", 30.0},
-				{"B", -5.0},
-				{"C", -5.0},
-				{"D", -10.0},
-				{"E", -5.0},
-				{"F", -5.0},
+				{Name: "A", Amount: money("30.0")},
+				{Name: "B", Amount: money("-5.0")},
+				{Name: "C", Amount: money("-5.0")},
+				{Name: "D", Amount: money("-10.0")},
+				{Name: "E", Amount: money("-5.0")},
+				{Name: "F", Amount: money("-5.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 0.0},
-					{"B", 0.0},
-					{"C", 0.0},
-					{"D", 0.0},
-					{"E", 0.0},
-					{"F", 0.0},
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
+					{Name: "D", Amount: money("0.0")},
+					{Name: "E", Amount: money("0.0")},
+					{Name: "F", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"B", "A", 5.0},
-					{"C", "A", 5.0},
-					{"D", "A", 10.0},
-					{"E", "A", 5.0},
-					{"F", "A", 5.0},
+					{From: "B", To: "A", Amount: money("5.0")},
+					{From: "C", To: "A", Amount: money("5.0")},
+					{From: "D", To: "A", Amount: money("10.0")},
+					{From: "E", To: "A", Amount: money("5.0")},
+					{From: "F", To: "A", Amount: money("5.0")},
 				},
 			},
 		},
 		{
 			name: "when more credits than debts",
 			input: Balances{
-				{"A", -30.0},
-				{"B", 5.0},
-				{"C", 5.0},
-				{"D", 10.0},
-				{"E", 5.0},
-				{"F", 5.0},
+				{Name: "A", Amount: money("-30.0")},
+				{Name: "B", Amount: money("5.0")},
+				{Name: "C", Amount: money("5.0")},
+				{Name: "D", Amount: money("10.0")},
+				{Name: "E", Amount: money("5.0")},
+				{Name: "F", Amount: money("5.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 0.0},
-					{"B", 0.0},
-					{"C", 0.0},
-					{"D", 0.0},
-					{"E", 0.0},
-					{"F", 0.0},
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
+					{Name: "D", Amount: money("0.0")},
+					{Name: "E", Amount: money("0.0")},
+					{Name: "F", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"A", "B", 5.0},
-					{"A", "C", 5.0},
-					{"A", "D", 10.0},
-					{"A", "E", 5.0},
-					{"A", "F", 5.0},
+					{From: "A", To: "B", Amount: money("5.0")},
+					{From: "A", To: "C", Amount: money("5.0")},
+					{From: "A", To: "D", Amount: money("10.0")},
+					{From: "A", To: "E", Amount: money("5.0")},
+					{From: "A", To: "F", Amount: money("5.0")},
 				},
 			},
 		},
 		{
 			name: "when more data in balances",
 			input: Balances{
-				{"A", 10.0},
-				{"B", 5.0},
-				{"C", -30.0},
-				{"D", -50.0},
-				{"E", 100.0},
-				{"F", -35.0},
+				{Name: "A", Amount: money("10.0")},
+				{Name: "B", Amount: money("5.0")},
+				{Name: "C", Amount: money("-30.0")},
+				{Name: "D", Amount: money("-50.0")},
+				{Name: "E", Amount: money("100.0")},
+				{Name: "F", Amount: money("-35.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 0.0},
-					{"B", 0.0},
-					{"C", 0.0},
-					{"D", 0.0},
-					{"E", 0.0},
-					{"F", 0.0},
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
+					{Name: "D", Amount: money("0.0")},
+					{Name: "E", Amount: money("0.0")},
+					{Name: "F", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"C", "B", 5.0},
-					{"C", "A", 10.0},
-					{"C", "E", 15.0},
-					{"F", "E", 35.0},
-					{"D", "E", 50.0},
+					{From: "C", To: "B", Amount: money("5.0")},
+					{From: "C", To: "A", Amount: money("10.0")},
+					{From: "C", To: "E", Amount: money("15.0")},
+					{From: "F", To: "E", Amount: money("35.0")},
+					{From: "D", To: "E", Amount: money("50.0")},
 				},
 			},
 		},
 		{
 			name: "when incomplete balances, incomplete pay to owned",
 			input: Balances{
-				{"A", 30.0},
-				{"B", -5.0},
-				{"C", -5.0},
+				{Name: "A", Amount: money("30.0")},
+				{Name: "B", Amount: money("-5.0")},
+				{Name: "C", Amount: money("-5.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 20.0},
-					{"B", 0.0},
-					{"C", 0.0},
+					{Name: "A", Amount: money("20.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"B", "A", 5.0},
-					{"C", "A", 5.0},
+					{From: "B", To: "A", Amount: money("5.0")},
+					{From: "C", To: "A", Amount: money("5.0")},
 				},
 			},
 		},
 		{
 			name: "when incomplete balances, complete pay to owned",
 			input: Balances{
-				{"A", 5.0},
-				{"B", -5.0},
-				{"C", -5.0},
+				{Name: "A", Amount: money("5.0")},
+				{Name: "B", Amount: money("-5.0")},
+				{Name: "C", Amount: money("-5.0")},
+			},
+			expected: Statement{
+				UpdatedBalances: Balances{
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("-5.0")},
+				},
+				Transactions: Transactions{
+					{From: "B", To: "A", Amount: money("5.0")},
+				},
+			},
+		},
+		{
+			name: "when decimal balances",
+			input: Balances{
+				{Name: "A", Amount: money("0.3")},
+				{Name: "B", Amount: money("-0.1")},
+				{Name: "C", Amount: money("-0.2")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 0.0},
-					{"B", 0.0},
-					{"C", -5.0},
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("0.0")},
+					{Name: "C", Amount: money("0.0")},
 				},
 				Transactions: Transactions{
-					{"B", "A", 5.0},
+					{From: "B", To: "A", Amount: money("0.1")},
+					{From: "C", To: "A", Amount: money("0.2")},
 				},
 			},
 		},
+		{
+			name: "when only debts left",
+			input: Balances{
+				{Name: "A", Amount: money("0.0")},
+				{Name: "B", Amount: money("-5.0")},
+			},
+			expected: Statement{
+				UpdatedBalances: Balances{
+					{Name: "A", Amount: money("0.0")},
+					{Name: "B", Amount: money("-5.0")},
+				},
+				Transactions: Transactions{},
+			},
+		},
 		{
 			name: "when single balance",
 			input: Balances{
-				{"A", 5.0},
+				{Name: "A", Amount: money("5.0")},
 			},
 			expected: Statement{
 				UpdatedBalances: Balances{
-					{"A", 5.0},
+					{Name: "A", Amount: money("5.0")},
 				},
 				Transactions: Transactions{},
 			},
@@ -272,6 +333,10 @@ func Test_Minimize_Transactions(t *testing.T) {
 	}
 }
 
+func money(s string) Money {
+	return MustParseMoney(s)
+}
+
 func sortedBalances(b Balances) Balances {
 	newB := append(Balances{}, b...)
 	slices.SortFunc(newB, func(a, b Balance) int {
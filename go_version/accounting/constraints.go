@@ -0,0 +1,240 @@
+package accounting
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrInfeasibleSettlement = errors.New("no feasible settlement")
+
+// Pair identifies a payer and a payee
+type Pair struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SettlementConstraints restricts how balances can be settled
+type SettlementConstraints struct {
+	// ForbiddenPairs payers that can not pay directly to payees, e.g. missing bank details
+	ForbiddenPairs []Pair `json:"forbidden_pairs,omitempty"`
+	// PreferredPayees people collecting payments for the group, who then pay the remaining creditors
+	PreferredPayees []string `json:"preferred_payees,omitempty"`
+	// MaxTransfer maximum amount a payer can send in a single transfer
+	MaxTransfer map[string]Money `json:"max_transfer,omitempty"`
+}
+
+// IsZero reports if there is no constraint at all
+func (c SettlementConstraints) IsZero() bool {
+	return len(c.ForbiddenPairs) == 0 && len(c.PreferredPayees) == 0 && len(c.MaxTransfer) == 0
+}
+
+// ParseConstraints builds constraints from their text form, as informed in query parameters or flags:
+// forbidden `payer:payee` pairs, preferred payees and max transfer `payer:amount` limits
+func ParseConstraints(forbidden, preferred, maxTransfer []string) (SettlementConstraints, error) {
+	var c SettlementConstraints
+	for _, v := range forbidden {
+		from, to, ok := strings.Cut(v, ":")
+		if !ok || from == "" || to == "" {
+			return SettlementConstraints{}, fmt.Errorf("%w: forbidden must be like `payer:payee`, got %q", ErrInvalidOption, v)
+		}
+		c.ForbiddenPairs = append(c.ForbiddenPairs, Pair{From: from, To: to})
+	}
+	c.PreferredPayees = preferred
+	for _, v := range maxTransfer {
+		name, amount, ok := strings.Cut(v, ":")
+		limit, err := ParseMoney(amount)
+		if !ok || name == "" || err != nil {
+			return SettlementConstraints{}, fmt.Errorf("%w: max_transfer must be like `payer:amount`, got %q", ErrInvalidOption, v)
+		}
+		if c.MaxTransfer == nil {
+			c.MaxTransfer = map[string]Money{}
+		}
+		c.MaxTransfer[name] = limit
+	}
+	return c, nil
+}
+
+// MaxConstrainedGroupSize is the largest group settled with constraints, the flow graph has an edge per pair of people
+const MaxConstrainedGroupSize = 100
+
+// maxTransfersPerPerson bounds how many transfers max transfer limits can break a settlement into,
+// relative to the group size
+const maxTransfersPerPerson = 100
+
+// transfer costs per unit of money, paying through a preferred payee must be cheaper than paying directly
+const (
+	directCost        = 2
+	toPreferredCost   = 0
+	fromPreferredCost = 1
+)
+
+// transferCost returns the cost per unit of money sent from a payer to a payee
+type transferCost func(from, to string, preferred map[string]bool) int64
+
+// preferredCost makes money go through preferred payees whenever possible
+func preferredCost(from, to string, preferred map[string]bool) int64 {
+	switch {
+	case preferred[to]:
+		return toPreferredCost
+	case preferred[from]:
+		return fromPreferredCost
+	default:
+		return directCost
+	}
+}
+
+// volumeCost makes every unit of money sent cost the same, minimizing the total amount moved
+func volumeCost(_, _ string, _ map[string]bool) int64 {
+	return 1
+}
+
+// settleConstrained settles the balances respecting the constraints, modeled as a min-cost flow over the debt graph:
+// debtors are sources of what they owe, creditors sinks of what they are owed,
+// and every allowed payer/payee pair is an edge, which lets money go through other people when a pair is forbidden
+func settleConstrained(balances Balances, constraints SettlementConstraints, cost transferCost) (Statement, error) {
+	if len(balances) > MaxConstrainedGroupSize {
+		return Statement{}, fmt.Errorf(
+			"%w: constraints support at most %d people, got %d", ErrInvalidOption, MaxConstrainedGroupSize, len(balances),
+		)
+	}
+	for name, limit := range constraints.MaxTransfer {
+		if limit.Sign() <= 0 {
+			return Statement{}, fmt.Errorf("%w: max transfer of %s must be positive", ErrInvalidOption, name)
+		}
+	}
+
+	scale := uint8(0)
+	for _, b := range balances {
+		scale = max(scale, b.Amount.scale)
+	}
+
+	forbidden := make(map[Pair]bool, len(constraints.ForbiddenPairs))
+	for _, p := range constraints.ForbiddenPairs {
+		forbidden[p] = true
+	}
+	preferred := make(map[string]bool, len(constraints.PreferredPayees))
+	for _, p := range constraints.PreferredPayees {
+		preferred[p] = true
+	}
+
+	n := len(balances)
+	source, sink := n, n+1
+	g := newFlowGraph(n + 2)
+
+	var debts, credits int64
+	for i, b := range balances {
+		amount := b.Amount.Rescale(scale).minor
+		switch {
+		case amount < 0:
+			g.addEdge(source, i, -amount, 0)
+			debts -= amount
+		case amount > 0:
+			g.addEdge(i, sink, amount, 0)
+			credits += amount
+		}
+	}
+
+	type pairEdge struct {
+		from, to, edge int
+	}
+	var pairEdges []pairEdge
+	for i, from := range balances {
+		for j, to := range balances {
+			if i == j || forbidden[Pair{From: from.Name, To: to.Name}] {
+				continue
+			}
+
+			c := cost(from.Name, to.Name, preferred)
+			pairEdges = append(pairEdges, pairEdge{from: i, to: j, edge: g.addEdge(i, j, debts, c)})
+		}
+	}
+
+	expected := min(debts, credits)
+	if flow := g.minCostMaxFlow(source, sink); flow < expected {
+		return Statement{}, infeasibleError(g, balances, source, scale)
+	}
+
+	// opposite flows between the same pair cancel each other
+	net := map[[2]int]int64{}
+	for _, pe := range pairEdges {
+		if f := g.edges[pe.edge].flow; f > 0 {
+			net[[2]int{pe.from, pe.to}] += f
+			net[[2]int{pe.to, pe.from}] -= f
+		}
+	}
+
+	transfers, maxTransfers := 0, len(balances)*maxTransfersPerPerson
+	for _, pe := range pairEdges {
+		if f := net[[2]int{pe.from, pe.to}]; f > 0 {
+			from := balances[pe.from].Name
+			transfers += transferCount(Money{minor: f, scale: scale}, constraints.MaxTransfer[from])
+			if transfers > maxTransfers {
+				return Statement{}, fmt.Errorf(
+					"%w: max transfer of %s needs more than %d transfers to settle the group",
+					ErrInvalidOption, from, maxTransfers,
+				)
+			}
+		}
+	}
+
+	updated := append(Balances{}, balances...)
+	transactions := Transactions{}
+	for _, pe := range pairEdges {
+		f := net[[2]int{pe.from, pe.to}]
+		if f <= 0 {
+			continue
+		}
+
+		amount := Money{minor: f, scale: scale}
+		from, to := balances[pe.from].Name, balances[pe.to].Name
+		updated[pe.from].Amount = updated[pe.from].Amount.Add(amount)
+		updated[pe.to].Amount = updated[pe.to].Amount.Sub(amount)
+		transactions = append(transactions, splitTransfer(from, to, amount, constraints.MaxTransfer[from])...)
+	}
+
+	slices.SortStableFunc(transactions, func(t1 Transaction, t2 Transaction) int {
+		return cmp.Or(cmp.Compare(t1.From, t2.From), cmp.Compare(t1.To, t2.To))
+	})
+	return Statement{UpdatedBalances: updated, Transactions: transactions}, nil
+}
+
+// splitTransfer breaks the amount into transfers of at most limit, a zero limit means no limit
+func splitTransfer(from, to string, amount Money, limit Money) Transactions {
+	if limit.IsZero() || amount.Cmp(limit) <= 0 {
+		return Transactions{{From: from, To: to, Amount: amount}}
+	}
+
+	var transactions Transactions
+	for amount.Cmp(limit) > 0 {
+		transactions = append(transactions, Transaction{From: from, To: to, Amount: limit.Rescale(amount.scale)})
+		amount = amount.Sub(limit)
+	}
+	return append(transactions, Transaction{From: from, To: to, Amount: amount})
+}
+
+// transferCount returns how many transfers splitTransfer breaks the amount into
+func transferCount(amount Money, limit Money) int {
+	if limit.IsZero() {
+		return 1
+	}
+	amount, limit = align(amount, limit)
+	return int((amount.minor + limit.minor - 1) / limit.minor)
+}
+
+// infeasibleError explains which debtors could not settle what they owe
+func infeasibleError(g *flowGraph, balances Balances, source int, scale uint8) error {
+	var unsettled []string
+	for _, ei := range g.adj[source] {
+		e := g.edges[ei]
+		if left := e.cap - e.flow; e.cap > 0 && left > 0 {
+			unsettled = append(unsettled, fmt.Sprintf("%s still owes %s", balances[e.to].Name, Money{minor: left, scale: scale}))
+		}
+	}
+	return fmt.Errorf(
+		"%w: %s, forbidden pairs leave no way to reach the creditors",
+		ErrInfeasibleSettlement, strings.Join(unsettled, ", "),
+	)
+}
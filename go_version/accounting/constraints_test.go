@@ -0,0 +1,134 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func Test_Settle_Constrained(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		input         Balances
+		constraints   SettlementConstraints
+		expected      Transactions
+		expectedError error
+	}{
+		{
+			name: "when forbidden pair goes through another member",
+			input: Balances{
+				{Name: "A", Amount: money("-10")},
+				{Name: "B", Amount: money("0")},
+				{Name: "C", Amount: money("10")},
+			},
+			constraints: SettlementConstraints{ForbiddenPairs: []Pair{{From: "A", To: "C"}}},
+			expected: Transactions{
+				{From: "A", To: "B", Amount: money("10")},
+				{From: "B", To: "C", Amount: money("10")},
+			},
+		},
+		{
+			name: "when preferred payee collects for the group",
+			input: Balances{
+				{Name: "A", Amount: money("-10")},
+				{Name: "B", Amount: money("-10")},
+				{Name: "C", Amount: money("10")},
+				{Name: "D", Amount: money("10")},
+			},
+			constraints: SettlementConstraints{PreferredPayees: []string{"C"}},
+			expected: Transactions{
+				{From: "A", To: "C", Amount: money("10")},
+				{From: "B", To: "C", Amount: money("10")},
+				{From: "C", To: "D", Amount: money("10")},
+			},
+		},
+		{
+			name: "when max transfer splits payments",
+			input: Balances{
+				{Name: "A", Amount: money("-250")},
+				{Name: "B", Amount: money("250")},
+			},
+			constraints: SettlementConstraints{MaxTransfer: map[string]Money{"A": money("100")}},
+			expected: Transactions{
+				{From: "A", To: "B", Amount: money("100")},
+				{From: "A", To: "B", Amount: money("100")},
+				{From: "A", To: "B", Amount: money("50")},
+			},
+		},
+		{
+			name: "when no path to creditors",
+			input: Balances{
+				{Name: "A", Amount: money("-10")},
+				{Name: "C", Amount: money("10")},
+			},
+			constraints:   SettlementConstraints{ForbiddenPairs: []Pair{{From: "A", To: "C"}}},
+			expectedError: ErrInfeasibleSettlement,
+		},
+		{
+			name: "when max transfer is not positive",
+			input: Balances{
+				{Name: "A", Amount: money("-10")},
+				{Name: "C", Amount: money("10")},
+			},
+			constraints:   SettlementConstraints{MaxTransfer: map[string]Money{"A": money("0")}},
+			expectedError: ErrInvalidOption,
+		},
+		{
+			name: "when max transfer needs too many transfers",
+			input: Balances{
+				{Name: "A", Amount: money("-1000000")},
+				{Name: "C", Amount: money("1000000")},
+			},
+			constraints:   SettlementConstraints{MaxTransfer: map[string]Money{"A": money("0.01")}},
+			expectedError: ErrInvalidOption,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := settleConstrained(s.input, s.constraints, preferredCost)
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if s.expectedError != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(s.expected, actual.Transactions) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual.Transactions)
+			}
+			for _, b := range actual.UpdatedBalances {
+				if !b.Amount.IsZero() {
+					t.Errorf("expected settled balances, got %+v", actual.UpdatedBalances)
+				}
+			}
+		})
+	}
+}
+
+func Test_Settle_Constrained_Group_Size(t *testing.T) {
+	balances := make(Balances, MaxConstrainedGroupSize+1)
+	for i := range balances {
+		balances[i] = Balance{Name: strconv.Itoa(i), Amount: money("10")}
+	}
+	balances[0].Amount = money("-1000")
+
+	_, err := settleConstrained(balances, SettlementConstraints{PreferredPayees: []string{"1"}}, volumeCost)
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrInvalidOption, err)
+	}
+}
+
+func Test_Service_Minimize_Constraints(t *testing.T) {
+	_, err := NewService().Minimize(context.Background(), Balances{
+		{Name: "A", Amount: money("-10")},
+		{Name: "C", Amount: money("10")},
+	}, WithConstraints(SettlementConstraints{ForbiddenPairs: []Pair{{From: "A", To: "C"}}}))
+
+	expected := "no feasible settlement: A still owes 10.00, forbidden pairs leave no way to reach the creditors"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, err)
+	}
+}
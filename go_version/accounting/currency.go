@@ -0,0 +1,157 @@
+package accounting
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMixedCurrencies = errors.New("amounts have different currencies, a settlement currency is required")
+	ErrRateNotFound    = errors.New("exchange rate not found")
+)
+
+// currencyScales lists ISO 4217 currencies whose minor unit is not DefaultScale
+var currencyScales = map[string]uint8{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+}
+
+// NormalizeCurrency returns the currency code in its canonical upper case form
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidCurrency reports if the code looks like an ISO 4217 currency code, three letters in any case
+func ValidCurrency(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// CurrencyScale returns the number of decimal places of the currency minor unit
+func CurrencyScale(code string) uint8 {
+	if s, ok := currencyScales[NormalizeCurrency(code)]; ok {
+		return s
+	}
+	return DefaultScale
+}
+
+// Convert multiplies the amount by rate, rounding half to even to the given scale
+func (m Money) Convert(rate *big.Rat, scale uint8) (Money, error) {
+	r := new(big.Rat).Mul(m.Rat(), rate)
+	if new(big.Rat).Abs(r).Cmp(maxRat) > 0 {
+		return Money{}, fmt.Errorf("%w: must not be above %s", ErrAmountTooLarge, MaxAmount)
+	}
+	n := new(big.Int).Mul(r.Num(), pow10(scale))
+	q, rem := new(big.Int).QuoRem(n, r.Denom(), new(big.Int))
+
+	// compare twice the remainder with the denominator to decide rounding
+	half := new(big.Int).Abs(rem)
+	half.Lsh(half, 1)
+	if c := half.Cmp(r.Denom()); c > 0 || (c == 0 && q.Bit(0) == 1) {
+		if rem.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Money{minor: q.Int64(), scale: scale}, nil
+}
+
+// converter converts amounts into a single target currency, keeping track of the rates used
+type converter struct {
+	rates  RateProvider
+	target string
+	used   map[[2]string]*big.Rat
+}
+
+func newConverter(rates RateProvider, target string) *converter {
+	return &converter{rates: rates, target: NormalizeCurrency(target), used: map[[2]string]*big.Rat{}}
+}
+
+// convert converts amount from the given currency into the target one
+// an empty currency on either side means the amount is already in the target currency
+func (c *converter) convert(amount Money, currency string) (Money, error) {
+	from := NormalizeCurrency(currency)
+	if from == "" || c.target == "" || from == c.target {
+		return amount, nil
+	}
+
+	key := [2]string{from, c.target}
+	rate, ok := c.used[key]
+	if !ok {
+		if c.rates == nil {
+			return Money{}, fmt.Errorf("%w: %s to %s, no rate provider configured", ErrRateNotFound, from, c.target)
+		}
+
+		var err error
+		if rate, err = c.rates.Rate(from, c.target); err != nil {
+			return Money{}, err
+		}
+		c.used[key] = rate
+	}
+	return amount.Convert(rate, CurrencyScale(c.target))
+}
+
+// usedRates returns the rates used so far, sorted by source currency
+func (c *converter) usedRates() []Rate {
+	if len(c.used) == 0 {
+		return nil
+	}
+
+	rates := make([]Rate, 0, len(c.used))
+	for k, r := range c.used {
+		rates = append(rates, Rate{From: k[0], To: k[1], Rate: r.FloatString(rateDecimals)})
+	}
+	slices.SortFunc(rates, func(r1 Rate, r2 Rate) int {
+		return cmp.Compare(r1.From, r2.From)
+	})
+	return rates
+}
+
+// settlementCurrency resolves the currency all amounts should be converted to
+// when none is requested, all amounts must share the same currency
+func settlementCurrency(requested string, currencies ...string) (string, error) {
+	if requested != "" {
+		if !ValidCurrency(requested) {
+			return "", fmt.Errorf("%w: currency must be a 3 letter code, got %q", ErrInvalidOption, requested)
+		}
+		return NormalizeCurrency(requested), nil
+	}
+
+	found := ""
+	for _, c := range currencies {
+		c = NormalizeCurrency(c)
+		switch {
+		case c == "" || c == found:
+		case found == "":
+			found = c
+		default:
+			return "", fmt.Errorf("%w: %s and %s", ErrMixedCurrencies, found, c)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,105 @@
+package accounting
+
+import (
+	"math/bits"
+)
+
+// DefaultExactLimit is the maximum number of non-zero balances the exact minimizer handles by default,
+// its cost grows with 2^n
+const DefaultExactLimit = 16
+
+// MaxExactLimit is the highest accepted limit for the exact minimizer, keeping memory use bounded
+const MaxExactLimit = 24
+
+// minimizeExact finds the true minimum number of transactions to balance to 0 the amount of each person
+// settling k people takes at most k-1 transactions, so the minimum comes from splitting balances into the maximum
+// number of subsets that sum to zero, each one then settled independently
+// balances that do not sum to zero can not be partitioned, so they are settled with the greedy minimizer
+func minimizeExact(balances Balances) Statement {
+	var nonZero, zero Balances
+	total := Money{}
+	for _, b := range balances {
+		if b.Amount.IsZero() {
+			zero = append(zero, b)
+			continue
+		}
+		nonZero = append(nonZero, b)
+		total = total.Add(b.Amount)
+	}
+
+	if !total.IsZero() || len(nonZero) < 3 {
+		return minimizeTransactions(balances)
+	}
+
+	scale := total.scale
+	amounts := make([]int64, len(nonZero))
+	for i, b := range nonZero {
+		amounts[i] = b.Amount.Rescale(scale).minor
+	}
+
+	statement := Statement{
+		UpdatedBalances: append(Balances{}, zero...),
+		Transactions:    make(Transactions, 0, len(nonZero)),
+	}
+	for _, subset := range zeroSumPartition(amounts) {
+		group := make(Balances, 0, bits.OnesCount32(subset))
+		for i := range nonZero {
+			if subset&(1<<i) != 0 {
+				group = append(group, nonZero[i])
+			}
+		}
+
+		s := minimizeTransactions(group)
+		statement.UpdatedBalances = append(statement.UpdatedBalances, s.UpdatedBalances...)
+		statement.Transactions = append(statement.Transactions, s.Transactions...)
+	}
+	return statement
+}
+
+// zeroSumPartition splits the amounts, which must sum to zero, into the maximum number of zero sum subsets
+// returned as bit masks of the amounts indexes
+func zeroSumPartition(amounts []int64) []uint32 {
+	n := len(amounts)
+	full := uint32(1)<<n - 1
+
+	// sums[mask] sum of amounts in mask, dp[mask] maximum zero sum subsets that can be removed one by one from mask
+	sums := make([]int64, full+1)
+	dp := make([]uint8, full+1)
+	for mask := uint32(1); mask <= full; mask++ {
+		low := bits.TrailingZeros32(mask)
+		sums[mask] = sums[mask&(mask-1)] + amounts[low]
+
+		best := uint8(0)
+		for rest := mask; rest != 0; rest &= rest - 1 {
+			i := bits.TrailingZeros32(rest)
+			best = max(best, dp[mask&^(1<<i)])
+		}
+		if sums[mask] == 0 {
+			best++
+		}
+		dp[mask] = best
+	}
+
+	// walking back from full mask, each zero sum mask found closes a subset
+	var subsets []uint32
+	mask, closed := full, full
+	for mask != 0 {
+		for rest := mask; rest != 0; rest &= rest - 1 {
+			i := bits.TrailingZeros32(rest)
+			next := mask &^ (1 << i)
+			gain := uint8(0)
+			if sums[mask] == 0 {
+				gain = 1
+			}
+			if dp[next]+gain == dp[mask] {
+				mask = next
+				break
+			}
+		}
+		if sums[mask] == 0 {
+			subsets = append(subsets, closed&^mask)
+			closed = mask
+		}
+	}
+	return subsets
+}
@@ -0,0 +1,147 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Minimize_Exact(t *testing.T) {
+	scenarios := []struct {
+		name                 string
+		input                Balances
+		expectedTransactions int
+	}{
+		{
+			name: "when greedy is not minimal",
+			input: Balances{
+				{Name: "A", Amount: money("2")},
+				{Name: "B", Amount: money("-4")},
+				{Name: "C", Amount: money("-7")},
+				{Name: "D", Amount: money("5")},
+				{Name: "E", Amount: money("-10")},
+				{Name: "F", Amount: money("14")},
+			},
+			expectedTransactions: 4,
+		},
+		{
+			name: "when pairs cancel each other",
+			input: Balances{
+				{Name: "A", Amount: money("5")},
+				{Name: "B", Amount: money("5")},
+				{Name: "C", Amount: money("-5")},
+				{Name: "D", Amount: money("-5")},
+			},
+			expectedTransactions: 2,
+		},
+		{
+			name: "when no zero sum subsets",
+			input: Balances{
+				{Name: "A", Amount: money("5")},
+				{Name: "B", Amount: money("5")},
+				{Name: "C", Amount: money("-3")},
+				{Name: "D", Amount: money("-7")},
+			},
+			expectedTransactions: 3,
+		},
+		{
+			name: "when zero balances",
+			input: Balances{
+				{Name: "A", Amount: money("30")},
+				{Name: "B", Amount: money("0")},
+				{Name: "C", Amount: money("-30")},
+			},
+			expectedTransactions: 1,
+		},
+		{
+			name: "when balances do not sum to zero falls back to greedy",
+			input: Balances{
+				{Name: "A", Amount: money("30")},
+				{Name: "B", Amount: money("-5")},
+				{Name: "C", Amount: money("-5")},
+			},
+			expectedTransactions: 2,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual := minimizeExact(s.input)
+
+			if len(actual.Transactions) != s.expectedTransactions {
+				t.Errorf("\nExpected:	%+v transactions\nGot:		%+v", s.expectedTransactions, actual.Transactions)
+			}
+
+			// applying the transactions must give the same updated balances
+			settled := map[string]Money{}
+			for _, b := range s.input {
+				settled[b.Name] = b.Amount
+			}
+			for _, tr := range actual.Transactions {
+				settled[tr.From] = settled[tr.From].Add(tr.Amount)
+				settled[tr.To] = settled[tr.To].Sub(tr.Amount)
+			}
+			for _, b := range actual.UpdatedBalances {
+				if !settled[b.Name].Equal(b.Amount) {
+					t.Errorf("\nExpected:	%s=%s\nGot:		%s=%s", b.Name, b.Amount, b.Name, settled[b.Name])
+				}
+			}
+			if len(actual.UpdatedBalances) != len(s.input) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.input, actual.UpdatedBalances)
+			}
+		})
+	}
+}
+
+func Test_Service_Minimize_Algorithm(t *testing.T) {
+	input := Balances{
+		{Name: "A", Amount: money("2")},
+		{Name: "B", Amount: money("-4")},
+		{Name: "C", Amount: money("-7")},
+		{Name: "D", Amount: money("5")},
+		{Name: "E", Amount: money("-10")},
+		{Name: "F", Amount: money("14")},
+	}
+
+	scenarios := []struct {
+		name                 string
+		service              *Service
+		opts                 []Option
+		expectedTransactions int
+	}{
+		{
+			name:                 "when auto",
+			service:              NewService(),
+			expectedTransactions: 4,
+		},
+		{
+			name:                 "when greedy",
+			service:              NewService(),
+			opts:                 []Option{WithAlgorithm(AlgorithmGreedy)},
+			expectedTransactions: 5,
+		},
+		{
+			name:                 "when exact above limit falls back to greedy",
+			service:              NewService(WithExactLimit(4)),
+			opts:                 []Option{WithAlgorithm(AlgorithmExact)},
+			expectedTransactions: 5,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.service.Minimize(context.Background(), input, s.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if len(actual.Transactions) != s.expectedTransactions {
+				t.Errorf("\nExpected:	%+v transactions\nGot:		%+v", s.expectedTransactions, actual.Transactions)
+			}
+		})
+	}
+
+	_, err := NewService().Minimize(context.Background(), input, WithAlgorithm("random"))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrInvalidOption, err)
+	}
+}
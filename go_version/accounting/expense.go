@@ -0,0 +1,104 @@
+package accounting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Contribution holds how much a person paid towards an expense or a transaction
+type Contribution struct {
+	Name   string `json:"name"`
+	Amount Money  `json:"amount"`
+}
+
+// Participant holds a person sharing an expense, which field is used depends on the expense split
+type Participant struct {
+	Name    string  `json:"name"`
+	Amount  Money   `json:"amount,omitzero"`
+	Percent Percent `json:"percent,omitempty"`
+	Shares  int64   `json:"shares,omitempty"`
+}
+
+// Expense is an amount paid by one or more people and shared between participants
+type Expense struct {
+	Description  string         `json:"description,omitempty"`
+	PaidBy       []Contribution `json:"paid_by"`
+	Total        Money          `json:"total"`
+	Currency     string         `json:"currency,omitempty"`
+	Split        SplitKind      `json:"split,omitempty"`
+	Participants []Participant  `json:"participants"`
+}
+
+// Expenses type alias for Expense slice
+type Expenses = []Expense
+
+// ExpandExpenses expands every expense into the transactions it represents
+func ExpandExpenses(expenses Expenses) (Transactions, error) {
+	transactions := make(Transactions, 0, len(expenses))
+	for i, e := range expenses {
+		t, err := e.Transactions()
+		if err != nil {
+			return nil, fmt.Errorf("expense %d: %w", i, err)
+		}
+		transactions = append(transactions, t...)
+	}
+	return transactions, nil
+}
+
+// Transactions expands the expense into a transaction for each contribution and each participant share
+// when there are many payers, the first one is owed every share and owes the others their contributions
+func (e Expense) Transactions() (Transactions, error) {
+	if e.Total.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: total must be positive", ErrInvalidSplit)
+	}
+	if len(e.PaidBy) == 0 {
+		return nil, fmt.Errorf("%w: no payers", ErrInvalidSplit)
+	}
+	if e.Currency != "" && !ValidCurrency(e.Currency) {
+		return nil, fmt.Errorf("%w: currency must be a 3 letter code, got %q", ErrInvalidSplit, e.Currency)
+	}
+
+	paid := e.Total.Zero()
+	for _, c := range e.PaidBy {
+		if strings.TrimSpace(c.Name) == "" {
+			return nil, fmt.Errorf("%w: payer name is required", ErrInvalidSplit)
+		}
+		if c.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: contribution of %s must be positive", ErrInvalidSplit, c.Name)
+		}
+		var err error
+		if paid, err = paid.CheckedAdd(c.Amount); err != nil {
+			return nil, fmt.Errorf("%w: contributions: %w", ErrInvalidSplit, err)
+		}
+	}
+	if !paid.Equal(e.Total) {
+		return nil, fmt.Errorf("%w: contributions sum to %s, expected %s", ErrInvalidSplit, paid, e.Total)
+	}
+
+	for _, p := range e.Participants {
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, fmt.Errorf("%w: participant name is required", ErrInvalidSplit)
+		}
+	}
+
+	strategy, err := SplitStrategyFor(e.Split)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := strategy.Split(e.Total, e.Participants)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first payer collects what the others paid and pays every share,
+	// which keeps the expansion linear in the number of payers and participants
+	collector := e.PaidBy[0].Name
+	transactions := make(Transactions, 0, len(e.PaidBy)-1+len(shares))
+	for _, c := range e.PaidBy[1:] {
+		transactions = append(transactions, Transaction{From: c.Name, To: collector, Amount: c.Amount, Currency: e.Currency})
+	}
+	for i, share := range shares {
+		transactions = append(transactions, Transaction{From: collector, To: e.Participants[i].Name, Amount: share, Currency: e.Currency})
+	}
+	return transactions, nil
+}
@@ -0,0 +1,143 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func Test_Expense_Transactions(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		input         Expense
+		expected      Transactions
+		expectedError error
+	}{
+		{
+			name: "when single payer equal split",
+			input: Expense{
+				PaidBy:       []Contribution{{Name: "A", Amount: money("30")}},
+				Total:        money("30"),
+				Currency:     "THB",
+				Participants: []Participant{{Name: "A"}, {Name: "B"}, {Name: "C"}},
+			},
+			expected: Transactions{
+				{From: "A", To: "A", Amount: money("10"), Currency: "THB"},
+				{From: "A", To: "B", Amount: money("10"), Currency: "THB"},
+				{From: "A", To: "C", Amount: money("10"), Currency: "THB"},
+			},
+		},
+		{
+			name: "when many payers",
+			input: Expense{
+				PaidBy: []Contribution{
+					{Name: "A", Amount: money("30")},
+					{Name: "B", Amount: money("10")},
+				},
+				Total:        money("40"),
+				Split:        SplitExact,
+				Participants: []Participant{{Name: "C", Amount: money("40")}},
+			},
+			expected: Transactions{
+				{From: "B", To: "A", Amount: money("10")},
+				{From: "A", To: "C", Amount: money("40")},
+			},
+		},
+		{
+			name: "when contributions do not sum to total",
+			input: Expense{
+				PaidBy:       []Contribution{{Name: "A", Amount: money("20")}},
+				Total:        money("30"),
+				Participants: []Participant{{Name: "B"}},
+			},
+			expectedError: ErrInvalidSplit,
+		},
+		{
+			name: "when no payers",
+			input: Expense{
+				Total:        money("30"),
+				Participants: []Participant{{Name: "B"}},
+			},
+			expectedError: ErrInvalidSplit,
+		},
+		{
+			name: "when total is not positive",
+			input: Expense{
+				PaidBy:       []Contribution{{Name: "A", Amount: money("-30")}},
+				Total:        money("-30"),
+				Participants: []Participant{{Name: "B"}},
+			},
+			expectedError: ErrInvalidSplit,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.input.Transactions()
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Expense_Transactions_Many_Payers(t *testing.T) {
+	const n = 3000
+	expense := Expense{Total: money("3000"), Split: SplitEqual}
+	for i := range n {
+		name := strconv.Itoa(i)
+		expense.PaidBy = append(expense.PaidBy, Contribution{Name: name, Amount: money("1")})
+		expense.Participants = append(expense.Participants, Participant{Name: name})
+	}
+
+	transactions, err := expense.Transactions()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if expected := 2*n - 1; len(transactions) != expected {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, len(transactions))
+	}
+
+	balances, err := NewService(WithMaxGroupSize(0)).Calculate(context.Background(), transactions)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	for _, b := range balances {
+		if b.Amount.Sign() != 0 {
+			t.Errorf("\nExpected:	%+v\nGot:		%+v", "settled", b)
+		}
+	}
+}
+
+func Test_Service_Calculate_Expenses(t *testing.T) {
+	var expenses Expenses
+	input := `[
+		{"paid_by":[{"name":"A","amount":90}],"total":90,"split":"shares","participants":[{"name":"A","shares":1},{"name":"B","shares":2}]},
+		{"paid_by":[{"name":"B","amount":"10"}],"total":"10","split":"percentage","participants":[{"name":"A","percent":"50"},{"name":"B","percent":50}]}
+	]`
+	if err := json.Unmarshal([]byte(input), &expenses); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	actual, err := NewService().CalculateExpenses(context.Background(), expenses)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := Balances{
+		{Name: "A", Amount: money("55")},
+		{Name: "B", Amount: money("-55")},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, actual)
+	}
+
+	_, err = NewService(WithMaxGroupSize(1)).CalculateExpenses(context.Background(), expenses)
+	assertFieldErrors(t, []FieldError{{Index: -1, Field: "names", Message: "must not have more than 1 distinct people, got 2"}}, err)
+}
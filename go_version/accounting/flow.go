@@ -0,0 +1,88 @@
+package accounting
+
+// flowGraph is a directed graph for min-cost max-flow, solved by successive shortest paths
+type flowGraph struct {
+	edges []flowEdge
+	adj   [][]int
+}
+
+type flowEdge struct {
+	to, rev   int
+	cap, cost int64
+	flow      int64
+}
+
+func newFlowGraph(nodes int) *flowGraph {
+	return &flowGraph{adj: make([][]int, nodes)}
+}
+
+// addEdge adds an edge from u to v and its residual reverse edge, returning the edge index
+func (g *flowGraph) addEdge(u, v int, capacity, cost int64) int {
+	g.adj[u] = append(g.adj[u], len(g.edges))
+	g.edges = append(g.edges, flowEdge{to: v, rev: len(g.edges) + 1, cap: capacity, cost: cost})
+	g.adj[v] = append(g.adj[v], len(g.edges))
+	g.edges = append(g.edges, flowEdge{to: u, rev: len(g.edges) - 1, cap: 0, cost: -cost})
+	return len(g.edges) - 2
+}
+
+// minCostMaxFlow pushes as much flow as possible from source to sink with the lowest total cost
+// returning the total flow
+func (g *flowGraph) minCostMaxFlow(source, sink int) int64 {
+	n := len(g.adj)
+	total := int64(0)
+	for {
+		// Bellman-Ford queue based (SPFA), residual edges may have negative costs
+		dist := make([]int64, n)
+		prevEdge := make([]int, n)
+		inQueue := make([]bool, n)
+		for i := range dist {
+			dist[i] = -1
+			prevEdge[i] = -1
+		}
+		dist[source] = 0
+		queue := []int{source}
+		inQueue[source] = true
+		reached := make([]bool, n)
+		reached[source] = true
+
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			inQueue[u] = false
+			for _, ei := range g.adj[u] {
+				e := g.edges[ei]
+				if e.cap-e.flow <= 0 {
+					continue
+				}
+				if nd := dist[u] + e.cost; !reached[e.to] || nd < dist[e.to] {
+					dist[e.to] = nd
+					reached[e.to] = true
+					prevEdge[e.to] = ei
+					if !inQueue[e.to] {
+						queue = append(queue, e.to)
+						inQueue[e.to] = true
+					}
+				}
+			}
+		}
+
+		if !reached[sink] {
+			return total
+		}
+
+		// bottleneck along the path
+		push := int64(-1)
+		for v := sink; v != source; v = g.edges[g.edges[prevEdge[v]].rev].to {
+			e := g.edges[prevEdge[v]]
+			if r := e.cap - e.flow; push < 0 || r < push {
+				push = r
+			}
+		}
+		for v := sink; v != source; v = g.edges[g.edges[prevEdge[v]].rev].to {
+			ei := prevEdge[v]
+			g.edges[ei].flow += push
+			g.edges[g.edges[ei].rev].flow -= push
+		}
+		total += push
+	}
+}
@@ -0,0 +1,69 @@
+package accounting
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrInvalidGroup  = errors.New("invalid group")
+	ErrUnknownMember = errors.New("unknown member")
+)
+
+// Member is a person taking part in a group
+type Member struct {
+	Name string `json:"name"`
+}
+
+// Group is a set of members sharing expenses, like the people on a trip
+type Group struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Currency  string    `json:"currency,omitempty"`
+	Members   []Member  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasMember reports if the name belongs to a group member
+func (g Group) HasMember(name string) bool {
+	return slices.ContainsFunc(g.Members, func(m Member) bool {
+		return m.Name == name
+	})
+}
+
+// validate checks the group has a name and its members are unique and named
+func (g Group) validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	}
+
+	if g.Currency != "" && !ValidCurrency(g.Currency) {
+		return fmt.Errorf("%w: currency must be a 3 letter code, got %q", ErrInvalidGroup, g.Currency)
+	}
+
+	seen := make(map[string]bool, len(g.Members))
+	for _, m := range g.Members {
+		if strings.TrimSpace(m.Name) == "" {
+			return fmt.Errorf("%w: member name is required", ErrInvalidGroup)
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("%w: duplicated member %s", ErrInvalidGroup, m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
+
+// newID generates a random identifier
+func newID() string {
+	b := make([]byte, 12)
+	// rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
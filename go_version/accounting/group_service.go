@@ -0,0 +1,354 @@
+package accounting
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// CreateGroup stores a new group, assigning its id and creation time
+func (s *Service) CreateGroup(ctx context.Context, group Group) (Group, error) {
+	if err := group.validate(); err != nil {
+		return Group{}, err
+	}
+
+	group.ID = newID()
+	group.Currency = NormalizeCurrency(group.Currency)
+	group.CreatedAt = s.now().UTC()
+	if group.Members == nil {
+		group.Members = []Member{}
+	}
+
+	if err := s.ledger.SaveGroup(group); err != nil {
+		return Group{}, fmt.Errorf("failed to save group: %w", err)
+	}
+	slog.InfoContext(ctx, "group created", "group_id", group.ID, "members", len(group.Members))
+	return group, nil
+}
+
+// AddMembers adds new members to an existing group
+func (s *Service) AddMembers(ctx context.Context, groupID string, members ...Member) (Group, error) {
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return Group{}, err
+	}
+
+	group.Members = append(group.Members, members...)
+	if err := group.validate(); err != nil {
+		return Group{}, err
+	}
+
+	if err := s.ledger.SaveGroup(group); err != nil {
+		return Group{}, fmt.Errorf("failed to save group: %w", err)
+	}
+	slog.InfoContext(ctx, "group members added", "group_id", group.ID, "members", len(members))
+	return group, nil
+}
+
+func (s *Service) Group(ctx context.Context, groupID string) (Group, error) {
+	return s.ledger.Group(groupID)
+}
+
+func (s *Service) Groups(ctx context.Context) ([]Group, error) {
+	return s.ledger.Groups()
+}
+
+// AddTransactions appends transactions to the group ledger, everyone involved must be a group member
+// every transaction gets its id and first version, undated transactions are dated when they are added
+func (s *Service) AddTransactions(ctx context.Context, groupID string, transactions ...Transaction) error {
+	if err := ValidateTransactions(transactions, s.maxGroupSize); err != nil {
+		return err
+	}
+
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	revisions := make([]Revision, 0, len(transactions))
+	for i, t := range transactions {
+		if err := checkMembers(group, i, t); err != nil {
+			return err
+		}
+		if t.Currency == "" {
+			transactions[i].Currency = group.Currency
+		}
+		if t.OccurredAt.IsZero() {
+			transactions[i].OccurredAt = now
+		}
+		transactions[i].ID = newID()
+		transactions[i].Version = 1
+		revisions = append(revisions, Revision{Transaction: transactions[i], Action: ActionCreated, Actor: ActorFrom(ctx), At: now})
+	}
+	if err := s.ledger.AppendRevisions(groupID, revisions...); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "group transactions added", "group_id", groupID, "transactions", len(transactions))
+	return nil
+}
+
+// GroupTransactions returns the current version of every transaction of the group not deleted
+func (s *Service) GroupTransactions(ctx context.Context, groupID string) (Transactions, error) {
+	revisions, err := s.ledger.Revisions(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return CurrentTransactions(revisions), nil
+}
+
+// UpdateTransaction corrects a group transaction, recording a new version of it
+// a version other than 0 must be the current one, so concurrent corrections are not lost
+func (s *Service) UpdateTransaction(ctx context.Context, groupID, transactionID string, version int, patch TransactionPatch) (Transaction, error) {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	group, current, err := s.currentRevision(groupID, transactionID, version)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	t := patch.apply(current.Transaction)
+	t.Currency = cmp.Or(t.Currency, group.Currency)
+	if err := ValidateTransactions(Transactions{t}, s.maxGroupSize); err != nil {
+		return Transaction{}, err
+	}
+	if err := checkMembers(group, 0, t); err != nil {
+		return Transaction{}, err
+	}
+
+	t.Version++
+	revision := Revision{Transaction: t, Action: ActionUpdated, Actor: ActorFrom(ctx), At: s.now().UTC()}
+	if err := s.ledger.AppendRevisions(groupID, revision); err != nil {
+		return Transaction{}, err
+	}
+	slog.InfoContext(ctx, "group transaction updated", "group_id", groupID, "transaction_id", t.ID, "version", t.Version)
+	return t, nil
+}
+
+// DeleteTransaction removes a transaction from the group balances, its history is kept
+// a version other than 0 must be the current one
+func (s *Service) DeleteTransaction(ctx context.Context, groupID, transactionID string, version int) error {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	_, current, err := s.currentRevision(groupID, transactionID, version)
+	if err != nil {
+		return err
+	}
+
+	t := current.Transaction
+	t.Version++
+	revision := Revision{Transaction: t, Action: ActionDeleted, Actor: ActorFrom(ctx), At: s.now().UTC()}
+	if err := s.ledger.AppendRevisions(groupID, revision); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "group transaction deleted", "group_id", groupID, "transaction_id", t.ID, "version", t.Version)
+	return nil
+}
+
+// TransactionHistory returns every revision of a group transaction, oldest first, deleted ones included
+func (s *Service) TransactionHistory(ctx context.Context, groupID, transactionID string) ([]Revision, error) {
+	revisions, err := s.ledger.Revisions(groupID)
+	if err != nil {
+		return nil, err
+	}
+	history := transactionHistory(revisions, transactionID)
+	if len(history) == 0 {
+		return nil, ErrTransactionNotFound
+	}
+	return history, nil
+}
+
+// currentRevision returns the group and the last revision of a transaction not deleted, checking its version
+func (s *Service) currentRevision(groupID, transactionID string, version int) (Group, Revision, error) {
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return Group{}, Revision{}, err
+	}
+	revisions, err := s.ledger.Revisions(groupID)
+	if err != nil {
+		return Group{}, Revision{}, err
+	}
+
+	history := transactionHistory(revisions, transactionID)
+	if len(history) == 0 || history[len(history)-1].Action == ActionDeleted {
+		return Group{}, Revision{}, ErrTransactionNotFound
+	}
+	current := history[len(history)-1]
+	if version != 0 && version != current.Version {
+		return Group{}, Revision{}, fmt.Errorf("%w: current version is %d, not %d", ErrVersionConflict, current.Version, version)
+	}
+	return group, current, nil
+}
+
+// checkMembers ensures everyone involved in the i-th transaction is a group member
+func checkMembers(group Group, i int, t Transaction) error {
+	for _, p := range t.Payments() {
+		for _, name := range []string{p.From, p.To} {
+			if !group.HasMember(name) {
+				return fmt.Errorf("%w: transaction %d, %s is not a member of %s", ErrUnknownMember, i, name, group.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ClearTransactions deletes every transaction of the group and voids its settlements
+// like single deletions it only appends to the ledger, so their history stays inspectable
+func (s *Service) ClearTransactions(ctx context.Context, groupID string) error {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	revisions, err := s.ledger.Revisions(groupID)
+	if err != nil {
+		return err
+	}
+	settlements, err := s.ledger.Settlements(groupID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	deleted := []Revision{}
+	for _, t := range CurrentTransactions(revisions) {
+		t.Version++
+		deleted = append(deleted, Revision{Transaction: t, Action: ActionDeleted, Actor: ActorFrom(ctx), At: now})
+	}
+	voided := activeSettlements(settlements)
+	for i := range voided {
+		voided[i].VoidedAt = now
+	}
+
+	if len(deleted) > 0 {
+		if err := s.ledger.AppendRevisions(groupID, deleted...); err != nil {
+			return err
+		}
+	}
+	if len(voided) > 0 {
+		if err := s.ledger.AppendSettlements(groupID, voided...); err != nil {
+			return err
+		}
+	}
+	slog.InfoContext(ctx, "group transactions cleared", "group_id", groupID, "transactions", len(deleted), "settlements", len(voided))
+	return nil
+}
+
+// GroupBalances calculates the current balance of every group member, debts paid down by settlements included
+// amounts are converted into the group currency, unless another settlement currency is requested
+func (s *Service) GroupBalances(ctx context.Context, groupID string, opts ...Option) (Balances, error) {
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.GroupTransactions(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	settlements, err := s.ledger.Settlements(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range activeSettlements(settlements) {
+		transactions = append(transactions, st.transaction())
+	}
+
+	// self transactions of 0 keep members without transactions in the balances
+	for _, m := range group.Members {
+		zero := NewMoney(0, CurrencyScale(group.Currency))
+		transactions = append(transactions, Transaction{From: m.Name, To: m.Name, Amount: zero, Currency: group.Currency})
+	}
+
+	// ledger transactions were validated when added
+	return s.calculate(transactions, groupOptions(group, opts)...)
+}
+
+// GroupStatement minimizes the transactions still outstanding to settle the group balances
+// and sums what was already settled between members, both up to the as of time when given
+func (s *Service) GroupStatement(ctx context.Context, groupID string, opts ...Option) (Statement, error) {
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	balances, err := s.GroupBalances(ctx, groupID, opts...)
+	if err != nil {
+		return Statement{}, err
+	}
+	statement, err := s.Minimize(ctx, balances, groupOptions(group, opts)...)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	settlements, err := s.ledger.Settlements(groupID)
+	if err != nil {
+		return Statement{}, err
+	}
+	settlements = activeSettlements(settlements)
+	if asOf := newOptions(opts).asOf; !asOf.IsZero() {
+		settlements = slices.DeleteFunc(settlements, func(st Settlement) bool {
+			return st.PaidAt.After(asOf)
+		})
+	}
+	if len(settlements) > 0 {
+		statement.Settled = settledTransactions(settlements)
+	}
+	return statement, nil
+}
+
+// RecordSettlement records a full or partial payment of an outstanding transaction of the group statement
+// the current statement is minimized with the given options, a zero amount pays the whole outstanding amount
+func (s *Service) RecordSettlement(ctx context.Context, groupID string, settlement Settlement, opts ...Option) (Settlement, error) {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	group, err := s.ledger.Group(groupID)
+	if err != nil {
+		return Settlement{}, err
+	}
+	if err := settlement.validate(group); err != nil {
+		return Settlement{}, err
+	}
+
+	// payments are always against what is owed now
+	opts = append(slices.Clone(opts), WithAsOf(time.Time{}))
+	statement, err := s.GroupStatement(ctx, groupID, opts...)
+	if err != nil {
+		return Settlement{}, err
+	}
+	if settlement, err = settlement.settle(statement); err != nil {
+		return Settlement{}, err
+	}
+
+	settlement.ID = newID()
+	if settlement.PaidAt.IsZero() {
+		settlement.PaidAt = s.now().UTC()
+	}
+	if err := s.ledger.AppendSettlements(groupID, settlement); err != nil {
+		return Settlement{}, err
+	}
+	slog.InfoContext(ctx, "group settlement recorded", "group_id", groupID, "settlement_id", settlement.ID)
+	return settlement, nil
+}
+
+// GroupSettlements returns every settlement recorded for the group, voided ones included
+func (s *Service) GroupSettlements(ctx context.Context, groupID string) (Settlements, error) {
+	settlements, err := s.ledger.Settlements(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return currentSettlements(settlements), nil
+}
+
+// groupOptions settles in the group currency by default, options can still override it
+func groupOptions(group Group, opts []Option) []Option {
+	if group.Currency == "" {
+		return opts
+	}
+	return append([]Option{WithSettlementCurrency(group.Currency)}, opts...)
+}
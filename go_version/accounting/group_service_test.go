@@ -0,0 +1,402 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Service_Groups(t *testing.T) {
+	service := NewService()
+	service.now = func() time.Time {
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	group, err := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Members: []Member{{Name: "A"}, {Name: "B"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if group.ID == "" || !group.CreatedAt.Equal(service.now()) {
+		t.Errorf("expected group id and creation time to be assigned, got %+v", group)
+	}
+
+	if _, err := service.AddMembers(context.Background(), group.ID, Member{Name: "C"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if err := service.AddTransactions(context.Background(), group.ID,
+		Transaction{From: "A", To: "B", Amount: money("40")},
+		Transaction{From: "B", To: "A", Amount: money("10")},
+	); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	transactions, err := service.GroupTransactions(context.Background(), group.ID)
+	if err != nil || len(transactions) != 2 || !transactions[0].OccurredAt.Equal(service.now()) {
+		t.Errorf("expected transactions to be dated when added, got %+v (%+v)", transactions, err)
+	}
+
+	balances, err := service.GroupBalances(context.Background(), group.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expectedBalances := Balances{
+		{Name: "A", Amount: money("30")},
+		{Name: "B", Amount: money("-30")},
+		{Name: "C", Amount: money("0")},
+	}
+	if !reflect.DeepEqual(expectedBalances, balances) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expectedBalances, balances)
+	}
+
+	statement, err := service.GroupStatement(context.Background(), group.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expectedTransactions := Transactions{{From: "B", To: "A", Amount: money("30")}}
+	if !reflect.DeepEqual(expectedTransactions, statement.Transactions) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expectedTransactions, statement.Transactions)
+	}
+
+	groups, err := service.Groups(context.Background())
+	if err != nil || len(groups) != 1 || len(groups[0].Members) != 3 {
+		t.Errorf("expected the group with 3 members, got %+v (%+v)", groups, err)
+	}
+}
+
+func Test_Service_Groups_Errors(t *testing.T) {
+	service := NewService()
+	group, _ := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Currency: "thb", Members: []Member{{Name: "A"}, {Name: "B"}}})
+
+	scenarios := []struct {
+		name          string
+		call          func() error
+		expectedError error
+	}{
+		{
+			name: "when group has no name",
+			call: func() error {
+				_, err := service.CreateGroup(context.Background(), Group{})
+				return err
+			},
+			expectedError: ErrInvalidGroup,
+		},
+		{
+			name: "when duplicated member",
+			call: func() error {
+				_, err := service.AddMembers(context.Background(), group.ID, Member{Name: "A"})
+				return err
+			},
+			expectedError: ErrInvalidGroup,
+		},
+		{
+			name: "when transaction with unknown member",
+			call: func() error {
+				return service.AddTransactions(context.Background(), group.ID, Transaction{From: "A", To: "Z", Amount: money("1")})
+			},
+			expectedError: ErrUnknownMember,
+		},
+		{
+			name: "when transaction with unknown contributor",
+			call: func() error {
+				return service.AddTransactions(context.Background(), group.ID, Transaction{
+					To: "A", Amount: money("2"), Contributors: []Contribution{{Name: "B", Amount: money("1")}, {Name: "Z", Amount: money("1")}},
+				})
+			},
+			expectedError: ErrUnknownMember,
+		},
+		{
+			name: "when group does not exist",
+			call: func() error {
+				_, err := service.GroupBalances(context.Background(), "missing")
+				return err
+			},
+			expectedError: ErrGroupNotFound,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := s.call(); !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+		})
+	}
+
+	if group.Currency != "THB" {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "THB", group.Currency)
+	}
+}
+
+func Test_Service_Settlements(t *testing.T) {
+	service := NewService()
+	service.now = func() time.Time {
+		return time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	}
+	group, _ := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Currency: "THB", Members: []Member{{Name: "A"}, {Name: "B"}, {Name: "C"}}})
+	_ = service.AddTransactions(context.Background(), group.ID,
+		Transaction{From: "A", To: "B", Amount: money("40")},
+		Transaction{From: "A", To: "C", Amount: money("20")},
+	)
+
+	scenarios := []struct {
+		name                string
+		settlement          Settlement
+		expectedSettlement  Settlement
+		expectedError       error
+		expectedOutstanding Transactions
+		expectedSettled     Transactions
+	}{
+		{
+			name:                "should record partial payment",
+			settlement:          Settlement{From: "B", To: "A", Amount: money("15")},
+			expectedSettlement:  Settlement{From: "B", To: "A", Amount: money("15"), Currency: "THB", PaidAt: service.now()},
+			expectedOutstanding: Transactions{{From: "B", To: "A", Amount: money("25"), Currency: "THB"}, {From: "C", To: "A", Amount: money("20"), Currency: "THB"}},
+			expectedSettled:     Transactions{{From: "B", To: "A", Amount: money("15"), Currency: "THB"}},
+		},
+		{
+			name:                "should pay the whole outstanding amount without amount",
+			settlement:          Settlement{From: "B", To: "A"},
+			expectedSettlement:  Settlement{From: "B", To: "A", Amount: money("25"), Currency: "THB", PaidAt: service.now()},
+			expectedOutstanding: Transactions{{From: "C", To: "A", Amount: money("20"), Currency: "THB"}},
+			expectedSettled:     Transactions{{From: "B", To: "A", Amount: money("40"), Currency: "THB"}},
+		},
+		{
+			name:          "when paying more than outstanding",
+			settlement:    Settlement{From: "C", To: "A", Amount: money("20.01")},
+			expectedError: ErrInvalidSettlement,
+		},
+		{
+			name:          "when nothing is owed",
+			settlement:    Settlement{From: "B", To: "A", Amount: money("1")},
+			expectedError: ErrInvalidSettlement,
+		},
+		{
+			name:          "when paying in another currency",
+			settlement:    Settlement{From: "C", To: "A", Amount: money("1"), Currency: "EUR"},
+			expectedError: ErrInvalidSettlement,
+		},
+		{
+			name:          "when paying a stranger",
+			settlement:    Settlement{From: "C", To: "Z", Amount: money("1")},
+			expectedError: ErrUnknownMember,
+		},
+		{
+			name:          "when negative amount",
+			settlement:    Settlement{From: "C", To: "A", Amount: money("-1")},
+			expectedError: ErrInvalidSettlement,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := service.RecordSettlement(context.Background(), group.ID, s.settlement)
+
+			if s.expectedError != nil {
+				if !errors.Is(err, s.expectedError) {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			s.expectedSettlement.ID = actual.ID
+			if actual.ID == "" || !reflect.DeepEqual(s.expectedSettlement, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedSettlement, actual)
+			}
+
+			statement, err := service.GroupStatement(context.Background(), group.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(s.expectedOutstanding, statement.Transactions) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedOutstanding, statement.Transactions)
+			}
+			if !reflect.DeepEqual(s.expectedSettled, statement.Settled) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedSettled, statement.Settled)
+			}
+		})
+	}
+}
+
+func Test_Service_Concurrent_Settlements(t *testing.T) {
+	service := NewService()
+	group, _ := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Currency: "THB", Members: []Member{{Name: "A"}, {Name: "B"}}})
+	_ = service.AddTransactions(context.Background(), group.ID, Transaction{From: "A", To: "B", Amount: money("40")})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.RecordSettlement(context.Background(), group.ID, Settlement{From: "B", To: "A"})
+		}()
+	}
+	wg.Wait()
+
+	settlements, err := service.GroupSettlements(context.Background(), group.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(settlements) != 1 || settlements[0].Amount.Cmp(money("40")) != 0 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "a single payment of 40", settlements)
+	}
+}
+
+func Test_Service_Settlement_Split_Transfers(t *testing.T) {
+	service := NewService()
+	group, _ := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Currency: "THB", Members: []Member{{Name: "A"}, {Name: "B"}}})
+	_ = service.AddTransactions(context.Background(), group.ID, Transaction{From: "A", To: "B", Amount: money("30")})
+	maxTransfer := WithConstraints(SettlementConstraints{MaxTransfer: map[string]Money{"B": money("10")}})
+
+	actual, err := service.RecordSettlement(context.Background(), group.ID, Settlement{From: "B", To: "A", Amount: money("30")}, maxTransfer)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !actual.Amount.Equal(money("30")) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", money("30"), actual.Amount)
+	}
+}
+
+func Test_Service_Settlement_Options_Not_Modified(t *testing.T) {
+	service := NewService()
+	group, _ := service.CreateGroup(context.Background(), Group{Name: "Bangkok", Currency: "THB", Members: []Member{{Name: "A"}, {Name: "B"}}})
+	_ = service.AddTransactions(context.Background(), group.ID, Transaction{From: "A", To: "B", Amount: money("40")})
+
+	asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := make([]Option, 1, 2)
+	opts[0] = WithSettlementCurrency("THB")
+	backing := append(opts, WithAsOf(asOf))
+
+	if _, err := service.RecordSettlement(context.Background(), group.ID, Settlement{From: "B", To: "A"}, opts...); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual := newOptions(backing).asOf; !actual.Equal(asOf) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", asOf, actual)
+	}
+}
+
+func Test_Service_Transaction_Revisions(t *testing.T) {
+	service := NewService()
+	service.now = func() time.Time {
+		return time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	}
+	ctx := WithActor(context.Background(), "A")
+	group, _ := service.CreateGroup(ctx, Group{Name: "Bangkok", Currency: "THB", Members: []Member{{Name: "A"}, {Name: "B"}}})
+	transactions := Transactions{{From: "A", To: "B", Amount: money("400")}, {From: "B", To: "A", Amount: money("10")}}
+	if err := service.AddTransactions(ctx, group.ID, transactions...); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	id := transactions[0].ID
+	if id == "" || transactions[0].Version != 1 || transactions[1].ID == id {
+		t.Fatalf("\nExpected:	distinct ids at version 1\nGot:		%+v", transactions)
+	}
+
+	amount := money("40")
+	stranger := "Z"
+	scenarios := []struct {
+		name             string
+		id               string
+		version          int
+		patch            *TransactionPatch
+		expectedVersion  int
+		expectedError    error
+		expectedBalances Balances
+	}{
+		{
+			name:             "should correct the amount",
+			id:               id,
+			version:          1,
+			patch:            &TransactionPatch{Amount: &amount},
+			expectedVersion:  2,
+			expectedBalances: Balances{{Name: "A", Amount: money("30"), Currency: "THB"}, {Name: "B", Amount: money("-30"), Currency: "THB"}},
+		},
+		{
+			name:          "when version is not the current one",
+			id:            id,
+			version:       1,
+			patch:         &TransactionPatch{Amount: &amount},
+			expectedError: ErrVersionConflict,
+		},
+		{
+			name:          "when correcting to a stranger",
+			id:            id,
+			patch:         &TransactionPatch{To: &stranger},
+			expectedError: ErrUnknownMember,
+		},
+		{
+			name:          "when transaction does not exist",
+			id:            "missing",
+			patch:         &TransactionPatch{Amount: &amount},
+			expectedError: ErrTransactionNotFound,
+		},
+		{
+			name:             "should delete without version",
+			id:               id,
+			expectedBalances: Balances{{Name: "A", Amount: money("-10"), Currency: "THB"}, {Name: "B", Amount: money("10"), Currency: "THB"}},
+		},
+		{
+			name:          "when transaction was deleted",
+			id:            id,
+			patch:         &TransactionPatch{Amount: &amount},
+			expectedError: ErrTransactionNotFound,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var err error
+			if s.patch != nil {
+				var updated Transaction
+				updated, err = service.UpdateTransaction(ctx, group.ID, s.id, s.version, *s.patch)
+				if err == nil && updated.Version != s.expectedVersion {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedVersion, updated.Version)
+				}
+			} else {
+				err = service.DeleteTransaction(ctx, group.ID, s.id, s.version)
+			}
+
+			if s.expectedError != nil {
+				if !errors.Is(err, s.expectedError) {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			balances, err := service.GroupBalances(ctx, group.ID)
+			if err != nil || !reflect.DeepEqual(s.expectedBalances, balances) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", s.expectedBalances, balances, err)
+			}
+		})
+	}
+
+	history, err := service.TransactionHistory(ctx, group.ID, id)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected := []Action{ActionCreated, ActionUpdated, ActionDeleted}
+	actual := []Action{}
+	for i, r := range history {
+		actual = append(actual, r.Action)
+		if r.Version != i+1 || r.Actor != "A" || r.At != service.now() {
+			t.Errorf("\nExpected:	version %d by A at %v\nGot:		%+v", i+1, service.now(), r)
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, actual)
+	}
+	if history[0].Amount != money("400") {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", money("400"), history[0].Amount)
+	}
+
+	if _, err := service.TransactionHistory(ctx, group.ID, "missing"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrTransactionNotFound, err)
+	}
+}
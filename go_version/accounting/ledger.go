@@ -0,0 +1,165 @@
+package accounting
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// LedgerRepository stores groups and the transactions recorded for them
+// transactions are kept as revisions, never rewritten, so their history stays inspectable
+type LedgerRepository interface {
+	// SaveGroup creates or replaces a group
+	SaveGroup(group Group) error
+	// Group returns the group or ErrGroupNotFound
+	Group(id string) (Group, error)
+	// Groups returns every group, ordered by creation
+	Groups() ([]Group, error)
+	// AppendRevisions adds transaction revisions at the end of the group ledger
+	AppendRevisions(groupID string, revisions ...Revision) error
+	// Revisions returns the group ledger in the order revisions were appended
+	Revisions(groupID string) ([]Revision, error)
+	// AppendSettlements adds settlements at the end of the group ledger, a voided settlement is appended again
+	AppendSettlements(groupID string, settlements ...Settlement) error
+	// Settlements returns the group settlements in the order they were appended
+	Settlements(groupID string) (Settlements, error)
+}
+
+// LedgerBalances calculates the balance of everyone in a group ledger in each currency, settlements included
+// unlike the group balances of the service amounts are not converted, balances are ordered by name then currency
+func LedgerBalances(revisions []Revision, settlements Settlements) (Balances, error) {
+	transactions := CurrentTransactions(revisions)
+	for _, st := range activeSettlements(settlements) {
+		transactions = append(transactions, st.transaction())
+	}
+
+	byCurrency := map[string]Transactions{}
+	for _, t := range transactions {
+		currency := NormalizeCurrency(t.Currency)
+		byCurrency[currency] = append(byCurrency[currency], t)
+	}
+	balances := Balances{}
+	for currency, transactions := range byCurrency {
+		calculated, err := calculateBalance(transactions)
+		if err != nil {
+			return nil, fmt.Errorf("%s balances: %w", currency, err)
+		}
+		for _, b := range calculated {
+			b.Currency = currency
+			balances = append(balances, b)
+		}
+	}
+	slices.SortFunc(balances, func(b1, b2 Balance) int {
+		return cmp.Or(cmp.Compare(b1.Name, b2.Name), cmp.Compare(b1.Currency, b2.Currency))
+	})
+	return balances, nil
+}
+
+// HealthChecker is implemented by repositories that can tell if they are able to serve requests
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// MemoryLedger is a LedgerRepository keeping everything in memory, meant for tests and stateless deployments
+type MemoryLedger struct {
+	mu          sync.RWMutex
+	groups      map[string]Group
+	revisions   map[string][]Revision
+	settlements map[string]Settlements
+}
+
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		groups:      map[string]Group{},
+		revisions:   map[string][]Revision{},
+		settlements: map[string]Settlements{},
+	}
+}
+
+func (ml *MemoryLedger) SaveGroup(group Group) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	group.Members = slices.Clone(group.Members)
+	ml.groups[group.ID] = group
+	return nil
+}
+
+func (ml *MemoryLedger) Group(id string) (Group, error) {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	g, ok := ml.groups[id]
+	if !ok {
+		return Group{}, ErrGroupNotFound
+	}
+	g.Members = slices.Clone(g.Members)
+	return g, nil
+}
+
+func (ml *MemoryLedger) Groups() ([]Group, error) {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	groups := make([]Group, 0, len(ml.groups))
+	for _, g := range ml.groups {
+		g.Members = slices.Clone(g.Members)
+		groups = append(groups, g)
+	}
+	SortGroups(groups)
+	return groups, nil
+}
+
+func (ml *MemoryLedger) AppendRevisions(groupID string, revisions ...Revision) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if _, ok := ml.groups[groupID]; !ok {
+		return ErrGroupNotFound
+	}
+	ml.revisions[groupID] = append(ml.revisions[groupID], revisions...)
+	return nil
+}
+
+func (ml *MemoryLedger) Revisions(groupID string) ([]Revision, error) {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	if _, ok := ml.groups[groupID]; !ok {
+		return nil, ErrGroupNotFound
+	}
+	return append([]Revision{}, ml.revisions[groupID]...), nil
+}
+
+func (ml *MemoryLedger) AppendSettlements(groupID string, settlements ...Settlement) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if _, ok := ml.groups[groupID]; !ok {
+		return ErrGroupNotFound
+	}
+	ml.settlements[groupID] = append(ml.settlements[groupID], settlements...)
+	return nil
+}
+
+func (ml *MemoryLedger) Settlements(groupID string) (Settlements, error) {
+	ml.mu.RLock()
+	defer ml.mu.RUnlock()
+
+	if _, ok := ml.groups[groupID]; !ok {
+		return nil, ErrGroupNotFound
+	}
+	return append(Settlements{}, ml.settlements[groupID]...), nil
+}
+
+// SortGroups orders groups by creation time, then by id
+func SortGroups(groups []Group) {
+	slices.SortFunc(groups, func(g1 Group, g2 Group) int {
+		if c := g1.CreatedAt.Compare(g2.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(g1.ID, g2.ID)
+	})
+}
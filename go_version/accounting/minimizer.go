@@ -0,0 +1,102 @@
+package accounting
+
+import (
+	"fmt"
+)
+
+// Algorithm identifies how transactions are minimized
+type Algorithm string
+
+const (
+	// AlgorithmAuto uses the exact minimizer when the group is small enough, greedy otherwise
+	AlgorithmAuto Algorithm = "auto"
+	// AlgorithmGreedy repeatedly matches the largest debtor with the largest creditor, fast but not always minimal
+	AlgorithmGreedy Algorithm = "greedy"
+	// AlgorithmExact partitions balances into zero sum subsets, always minimal but exponential on the group size
+	AlgorithmExact Algorithm = "exact"
+)
+
+// Objective identifies what a settlement optimizes for
+type Objective string
+
+const (
+	// ObjectiveCount fewest transactions, e.g. to pay less bank fees
+	ObjectiveCount Objective = "count"
+	// ObjectiveVolume least total amount of money changing hands
+	ObjectiveVolume Objective = "volume"
+	// ObjectivePayers fewest distinct payers for each payee
+	ObjectivePayers Objective = "payers"
+)
+
+// ParseObjective validates an objective name, an empty name means ObjectiveCount
+func ParseObjective(name string) (Objective, error) {
+	switch o := Objective(name); o {
+	case "":
+		return ObjectiveCount, nil
+	case ObjectiveCount, ObjectiveVolume, ObjectivePayers:
+		return o, nil
+	default:
+		return "", fmt.Errorf("%w: unknown objective %q", ErrInvalidOption, name)
+	}
+}
+
+// ParseAlgorithm validates an algorithm name, an empty name means AlgorithmAuto
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch a := Algorithm(name); a {
+	case "":
+		return AlgorithmAuto, nil
+	case AlgorithmAuto, AlgorithmGreedy, AlgorithmExact:
+		return a, nil
+	default:
+		return "", fmt.Errorf("%w: unknown algorithm %q", ErrInvalidOption, name)
+	}
+}
+
+// minimize dispatches to the minimizer for the algorithm
+// the exact one falls back to greedy when there are more non-zero balances than exactLimit
+func minimize(balances Balances, algorithm Algorithm, exactLimit int) Statement {
+	if algorithm == AlgorithmGreedy {
+		return minimizeTransactions(balances)
+	}
+
+	nonZero := 0
+	for _, b := range balances {
+		if !b.Amount.IsZero() {
+			nonZero++
+		}
+	}
+	if nonZero > exactLimit {
+		return minimizeTransactions(balances)
+	}
+	return minimizeExact(balances)
+}
+
+// settle settles the balances according to the objective and constraints of the options
+func settle(balances Balances, o options, exactLimit int) (Statement, error) {
+	if _, err := ParseAlgorithm(string(o.algorithm)); err != nil {
+		return Statement{}, err
+	}
+	objective, err := ParseObjective(string(o.objective))
+	if err != nil {
+		return Statement{}, err
+	}
+
+	switch objective {
+	case ObjectiveVolume:
+		if o.constraints.IsZero() {
+			// paying creditors directly never moves more than the debts, so any direct settlement has the least volume
+			return minimize(balances, o.algorithm, exactLimit), nil
+		}
+		return settleConstrained(balances, o.constraints, volumeCost)
+	case ObjectivePayers:
+		if !o.constraints.IsZero() {
+			return Statement{}, fmt.Errorf("%w: objective %s does not support constraints", ErrInvalidOption, objective)
+		}
+		return minimizePayers(balances), nil
+	default:
+		if !o.constraints.IsZero() {
+			return settleConstrained(balances, o.constraints, preferredCost)
+		}
+		return minimize(balances, o.algorithm, exactLimit), nil
+	}
+}
@@ -0,0 +1,280 @@
+package accounting
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultScale is the number of decimal places used when an amount does not need more
+	DefaultScale uint8 = 2
+	// MaxScale is the maximum number of decimal places an amount can carry
+	MaxScale uint8 = 6
+)
+
+var (
+	ErrInvalidAmount  = errors.New("invalid amount")
+	ErrAmountTooLarge = errors.New("amount out of range")
+)
+
+const (
+	// maxAmountLength and maxExponent bound what is parsed, so huge exponents are rejected before being expanded
+	maxAmountLength = 64
+	maxExponent     = 64
+)
+
+// MaxAmount is the largest absolute amount accepted, small enough for any amount to be rescaled to MaxScale
+// and for sums of many of them to be checked without overflowing
+var MaxAmount = NewMoney(1_000_000_000_000, 0)
+
+var maxRat = MaxAmount.Rat()
+
+// Money is an exact fixed-point amount, kept as integer minor units plus the number of decimal places (scale)
+// the zero value is a valid zero amount
+type Money struct {
+	minor int64
+	scale uint8
+}
+
+// NewMoney creates an amount from its minor units, e.g. NewMoney(1050, 2) is 10.50
+func NewMoney(minor int64, scale uint8) Money {
+	return Money{minor: minor, scale: scale}
+}
+
+// ParseMoney parses a decimal representation like "10", "-0.1" or "1e2" into an exact amount
+// the resulting scale is at least DefaultScale, and it fails if more than MaxScale decimal places are needed
+// or the amount is above MaxAmount
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > maxAmountLength {
+		return Money{}, fmt.Errorf("%w: longer than %d characters", ErrInvalidAmount, maxAmountLength)
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(s[i+1:]); err == nil && (exp > maxExponent || exp < -maxExponent) {
+			return Money{}, fmt.Errorf("%w: exponent of %q is out of range", ErrInvalidAmount, s)
+		}
+	}
+	// big.Rat also accepts fractions like "1/3", which are not decimals
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return moneyFromRat(r, DefaultScale)
+}
+
+// MustParseMoney same as ParseMoney but panics on error, meant for constants and tests
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// moneyFromRat finds the smallest scale, starting from minScale, that represents r exactly
+func moneyFromRat(r *big.Rat, minScale uint8) (Money, error) {
+	if new(big.Rat).Abs(r).Cmp(maxRat) > 0 {
+		return Money{}, fmt.Errorf("%w: must not be above %s", ErrAmountTooLarge, MaxAmount)
+	}
+	for scale := minScale; scale <= MaxScale; scale++ {
+		n := new(big.Int).Mul(r.Num(), pow10(scale))
+		q, rem := new(big.Int).QuoRem(n, r.Denom(), new(big.Int))
+		if rem.Sign() != 0 {
+			continue
+		}
+		return Money{minor: q.Int64(), scale: scale}, nil
+	}
+	return Money{}, fmt.Errorf("%w: %s has more than %d decimal places", ErrInvalidAmount, r.String(), MaxScale)
+}
+
+func pow10(scale uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+}
+
+// Minor returns the amount in minor units of its scale
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Scale returns the number of decimal places of the amount
+func (m Money) Scale() uint8 {
+	return m.scale
+}
+
+// Rescale returns the same amount with a bigger scale, a smaller scale is ignored as it could lose precision
+// amounts up to MaxAmount always fit, use CheckedAdd to sum amounts that could not
+func (m Money) Rescale(scale uint8) Money {
+	for m.scale < scale {
+		m.minor *= 10
+		m.scale++
+	}
+	return m
+}
+
+// rescale is Rescale reporting whether the amount still fits in its minor units
+func (m Money) rescale(scale uint8) (Money, bool) {
+	for m.scale < scale {
+		if m.minor > math.MaxInt64/10 || m.minor < -math.MaxInt64/10 {
+			return m, false
+		}
+		m.minor *= 10
+		m.scale++
+	}
+	return m, true
+}
+
+// align returns both amounts with the same scale
+func align(a, b Money) (Money, Money) {
+	s := max(a.scale, b.scale)
+	return a.Rescale(s), b.Rescale(s)
+}
+
+func (m Money) Add(o Money) Money {
+	a, b := align(m, o)
+	return Money{minor: a.minor + b.minor, scale: a.scale}
+}
+
+func (m Money) Sub(o Money) Money {
+	a, b := align(m, o)
+	return Money{minor: a.minor - b.minor, scale: a.scale}
+}
+
+// CheckedAdd is Add failing with ErrAmountTooLarge instead of overflowing, meant for sums of many amounts
+func (m Money) CheckedAdd(o Money) (Money, error) {
+	s := max(m.scale, o.scale)
+	a, okA := m.rescale(s)
+	b, okB := o.rescale(s)
+	if !okA || !okB || (b.minor > 0 && a.minor > math.MaxInt64-b.minor) || (b.minor < 0 && a.minor < -math.MaxInt64-b.minor) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrAmountTooLarge, m, o)
+	}
+	return Money{minor: a.minor + b.minor, scale: s}, nil
+}
+
+// checkRange fails with ErrAmountTooLarge when the amount is above MaxAmount, e.g. a sum of many amounts
+func checkRange(m Money) error {
+	if m.Abs().Cmp(MaxAmount) > 0 {
+		return fmt.Errorf("%w: %s is above %s", ErrAmountTooLarge, m, MaxAmount)
+	}
+	return nil
+}
+
+// CheckedSub is Sub failing with ErrAmountTooLarge instead of overflowing
+func (m Money) CheckedSub(o Money) (Money, error) {
+	return m.CheckedAdd(o.Neg())
+}
+
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, scale: m.scale}
+}
+
+func (m Money) Abs() Money {
+	if m.minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Cmp compares two amounts regardless of their scale, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	s := max(m.scale, o.scale)
+	a, okA := m.rescale(s)
+	b, okB := o.rescale(s)
+	if !okA || !okB {
+		return m.Rat().Cmp(o.Rat())
+	}
+	return cmp.Compare(a.minor, b.minor)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount
+func (m Money) Sign() int {
+	return cmp.Compare(m.minor, 0)
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// Equal reports if both amounts are the same regardless of their scale
+func (m Money) Equal(o Money) bool {
+	return m.Cmp(o) == 0
+}
+
+// Zero returns a zero amount keeping the same scale
+func (m Money) Zero() Money {
+	return Money{scale: m.scale}
+}
+
+// MinMoney returns the lowest of two amounts
+func MinMoney(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// MaxMoney returns the highest of two amounts
+func MaxMoney(a, b Money) Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// Rat returns the exact rational value of the amount
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.minor), pow10(m.scale))
+}
+
+// String returns the exact decimal representation with all decimal places of its scale, e.g. "-10.50"
+func (m Money) String() string {
+	digits := strconv.FormatUint(absUint(m.minor), 10)
+	sign := ""
+	if m.minor < 0 {
+		sign = "-"
+	}
+	if m.scale == 0 {
+		return sign + digits
+	}
+	if pad := int(m.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	cut := len(digits) - int(m.scale)
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+// MarshalJSON writes the amount as an exact JSON number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a JSON string holding a decimal
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	parsed, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
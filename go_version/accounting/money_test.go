@@ -0,0 +1,200 @@
+package accounting
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_Parse_Money(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		input         string
+		expected      Money
+		expectedError error
+	}{
+		{
+			name:     "when integer",
+			input:    "40",
+			expected: NewMoney(4000, 2),
+		},
+		{
+			name:     "when negative decimal",
+			input:    "-0.1",
+			expected: NewMoney(-10, 2),
+		},
+		{
+			name:     "when more decimal places than default",
+			input:    "10.125",
+			expected: NewMoney(10125, 3),
+		},
+		{
+			name:     "when exponent",
+			input:    "1e2",
+			expected: NewMoney(10000, 2),
+		},
+		{
+			name:          "when too many decimal places",
+			input:         "0.0000001",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "when not a number",
+			input:         "NaN",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "when fraction",
+			input:         "1/2",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "when out of range",
+			input:         "100000000000000000",
+			expectedError: ErrAmountTooLarge,
+		},
+		{
+			name:     "when max amount",
+			input:    "-1000000000000",
+			expected: NewMoney(-100000000000000, 2),
+		},
+		{
+			name:          "when just above max amount",
+			input:         "1000000000000.01",
+			expectedError: ErrAmountTooLarge,
+		},
+		{
+			name:          "when huge exponent",
+			input:         "1e1000000",
+			expectedError: ErrInvalidAmount,
+		},
+		{
+			name:          "when too long",
+			input:         strings.Repeat("1", 65),
+			expectedError: ErrInvalidAmount,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := ParseMoney(s.input)
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if actual != s.expected {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Money_Arithmetic(t *testing.T) {
+	sum := money("0.1").Add(money("0.2"))
+	if sum.String() != "0.30" || !sum.Equal(money("0.3")) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "0.30", sum)
+	}
+
+	diff := money("1").Sub(money("0.125"))
+	if diff.String() != "0.875" {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "0.875", diff)
+	}
+
+	if money("-0.05").String() != "-0.05" {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "-0.05", money("-0.05"))
+	}
+
+	if money("10.00").Cmp(NewMoney(10, 0)) != 0 {
+		t.Errorf("expected amounts with different scale to be equal")
+	}
+
+	// rescaling the first amount to the scale of the second one does not fit
+	if NewMoney(math.MaxInt64, 0).Cmp(NewMoney(1, 6)) != 1 {
+		t.Errorf("expected amounts out of range when rescaled to be compared exactly")
+	}
+}
+
+func Test_Money_Checked_Arithmetic(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		operation     func() (Money, error)
+		expected      Money
+		expectedError error
+	}{
+		{
+			name:      "should add amounts with different scale",
+			operation: func() (Money, error) { return money("0.1").CheckedAdd(money("0.125")) },
+			expected:  NewMoney(225, 3),
+		},
+		{
+			name:          "when sum overflows",
+			operation:     func() (Money, error) { return NewMoney(math.MaxInt64, 2).CheckedAdd(NewMoney(1, 2)) },
+			expectedError: ErrAmountTooLarge,
+		},
+		{
+			name:          "when difference overflows",
+			operation:     func() (Money, error) { return NewMoney(-math.MaxInt64, 2).CheckedSub(NewMoney(1, 2)) },
+			expectedError: ErrAmountTooLarge,
+		},
+		{
+			name:          "when rescale overflows",
+			operation:     func() (Money, error) { return NewMoney(math.MaxInt64/2, 2).CheckedAdd(NewMoney(1, 6)) },
+			expectedError: ErrAmountTooLarge,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.operation()
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if actual != s.expected {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Money_JSON(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "when number",
+			input:    `{"from":"A","to":"B","amount":40}`,
+			expected: `{"from":"A","to":"B","amount":40.00}`,
+		},
+		{
+			name:     "when string",
+			input:    `{"from":"A","to":"B","amount":"0.10"}`,
+			expected: `{"from":"A","to":"B","amount":0.10}`,
+		},
+		{
+			name:     "when more decimal places",
+			input:    `{"from":"A","to":"B","amount":-1.005}`,
+			expected: `{"from":"A","to":"B","amount":-1.005}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var tr Transaction
+			if err := json.Unmarshal([]byte(s.input), &tr); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			actual, _ := json.Marshal(tr)
+			if string(actual) != s.expected {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, string(actual))
+			}
+		})
+	}
+
+	var tr Transaction
+	if err := json.Unmarshal([]byte(`{"amount":"abc"}`), &tr); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrInvalidAmount, err)
+	}
+}
@@ -0,0 +1,61 @@
+package accounting
+
+import (
+	"cmp"
+	"slices"
+)
+
+// minimizePayers settles balances keeping the number of distinct payers of each payee low
+// creditors, from the largest, are paid by the debtor owing the least that still covers the whole credit,
+// only when no single debtor can cover it the largest debtors are combined
+func minimizePayers(balances Balances) Statement {
+	updated := append(Balances{}, balances...)
+
+	var debtors, creditors []int
+	for i, b := range updated {
+		switch b.Amount.Sign() {
+		case -1:
+			debtors = append(debtors, i)
+		case 1:
+			creditors = append(creditors, i)
+		}
+	}
+	slices.SortStableFunc(creditors, func(i, j int) int {
+		return updated[j].Amount.Cmp(updated[i].Amount)
+	})
+
+	transactions := make(Transactions, 0, len(balances))
+	for _, c := range creditors {
+		for updated[c].Amount.Sign() > 0 {
+			credit := updated[c].Amount
+
+			// best fit: the smallest debt covering the whole credit, or else the largest debt
+			payer, covers := -1, false
+			for _, d := range debtors {
+				debt := updated[d].Amount.Abs()
+				switch {
+				case debt.IsZero():
+				case debt.Cmp(credit) >= 0:
+					if !covers || debt.Cmp(updated[payer].Amount.Abs()) < 0 {
+						payer, covers = d, true
+					}
+				case !covers && (payer < 0 || debt.Cmp(updated[payer].Amount.Abs()) > 0):
+					payer = d
+				}
+			}
+			if payer < 0 {
+				break
+			}
+
+			amount := MinMoney(credit, updated[payer].Amount.Abs())
+			transactions = append(transactions, Transaction{From: updated[payer].Name, To: updated[c].Name, Amount: amount})
+			updated[payer].Amount = updated[payer].Amount.Add(amount)
+			updated[c].Amount = updated[c].Amount.Sub(amount)
+		}
+	}
+
+	slices.SortStableFunc(transactions, func(t1 Transaction, t2 Transaction) int {
+		return cmp.Or(cmp.Compare(t1.To, t2.To), cmp.Compare(t1.From, t2.From))
+	})
+	return Statement{UpdatedBalances: updated, Transactions: transactions}
+}
@@ -0,0 +1,132 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Minimize_Payers(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		input    Balances
+		expected Transactions
+	}{
+		{
+			name: "when single debtor can cover each payee",
+			input: Balances{
+				{Name: "A", Amount: money("-10")},
+				{Name: "B", Amount: money("-5")},
+				{Name: "C", Amount: money("5")},
+				{Name: "D", Amount: money("8")},
+				{Name: "E", Amount: money("2")},
+			},
+			expected: Transactions{
+				{From: "B", To: "C", Amount: money("5")},
+				{From: "A", To: "D", Amount: money("8")},
+				{From: "A", To: "E", Amount: money("2")},
+			},
+		},
+		{
+			name: "when payee needs many payers",
+			input: Balances{
+				{Name: "A", Amount: money("-5")},
+				{Name: "B", Amount: money("-5")},
+				{Name: "C", Amount: money("10")},
+			},
+			expected: Transactions{
+				{From: "A", To: "C", Amount: money("5")},
+				{From: "B", To: "C", Amount: money("5")},
+			},
+		},
+		{
+			name: "when incomplete balances",
+			input: Balances{
+				{Name: "A", Amount: money("30")},
+				{Name: "B", Amount: money("-5")},
+			},
+			expected: Transactions{
+				{From: "B", To: "A", Amount: money("5")},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual := minimizePayers(s.input)
+
+			if !reflect.DeepEqual(s.expected, actual.Transactions) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual.Transactions)
+			}
+		})
+	}
+}
+
+func Test_Service_Minimize_Objective(t *testing.T) {
+	input := Balances{
+		{Name: "A", Amount: money("-10")},
+		{Name: "B", Amount: money("-10")},
+		{Name: "C", Amount: money("10")},
+		{Name: "D", Amount: money("10")},
+	}
+	constraints := WithConstraints(SettlementConstraints{PreferredPayees: []string{"C"}})
+
+	scenarios := []struct {
+		name           string
+		opts           []Option
+		expectedVolume Money
+		expectedError  error
+	}{
+		{
+			name:           "when count with preferred payee",
+			opts:           []Option{constraints},
+			expectedVolume: money("30"),
+		},
+		{
+			name:           "when volume with preferred payee",
+			opts:           []Option{constraints, WithObjective(ObjectiveVolume)},
+			expectedVolume: money("20"),
+		},
+		{
+			name:           "when volume without constraints",
+			opts:           []Option{WithObjective(ObjectiveVolume)},
+			expectedVolume: money("20"),
+		},
+		{
+			name:           "when payers",
+			opts:           []Option{WithObjective(ObjectivePayers)},
+			expectedVolume: money("20"),
+		},
+		{
+			name:          "when payers with constraints",
+			opts:          []Option{constraints, WithObjective(ObjectivePayers)},
+			expectedError: ErrInvalidOption,
+		},
+		{
+			name:          "when unknown objective",
+			opts:          []Option{WithObjective("random")},
+			expectedError: ErrInvalidOption,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := NewService().Minimize(context.Background(), input, s.opts...)
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if s.expectedError != nil {
+				return
+			}
+
+			volume := Money{}
+			for _, tr := range actual.Transactions {
+				volume = volume.Add(tr.Amount)
+			}
+			if !volume.Equal(s.expectedVolume) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", s.expectedVolume, volume, actual.Transactions)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package accounting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// HundredPercent is 100% in basis points
+const HundredPercent Percent = 100_00
+
+// Percent is an exact percentage kept in basis points (hundredths of a percent), e.g. 33.33% is 3333
+type Percent int64
+
+// ParsePercent parses a decimal percentage with up to 2 decimal places, like "33.33"
+func ParsePercent(s string) (Percent, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || strings.Contains(s, "/") {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid percentage %q, at most 2 decimal places are allowed", s)
+	}
+	return Percent(r.Num().Int64()), nil
+}
+
+func (p Percent) String() string {
+	return NewMoney(int64(p), 2).String()
+}
+
+// MarshalJSON writes the percentage as an exact JSON number
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a JSON string holding a decimal
+func (p *Percent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	parsed, err := ParsePercent(string(data))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,134 @@
+package accounting
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// rateDecimals number of decimal places used when reporting a rate
+const rateDecimals = 8
+
+// RateProvider provides exchange rates between currencies
+type RateProvider interface {
+	// Rate returns how much of `to` currency one unit of `from` currency is worth
+	Rate(from, to string) (*big.Rat, error)
+}
+
+// Rate holds an exchange rate used to convert amounts
+type Rate struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Rate string `json:"rate"`
+}
+
+// StaticRates is a RateProvider backed by a fixed table of rates relative to a base currency
+type StaticRates struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+// NewStaticRates creates a rate table, each rate is how much of the currency one unit of base is worth,
+// e.g. NewStaticRates("EUR", map[string]string{"THB": "38.5"})
+func NewStaticRates(base string, rates map[string]string) (*StaticRates, error) {
+	sr := &StaticRates{
+		base:  NormalizeCurrency(base),
+		rates: make(map[string]*big.Rat, len(rates)+1),
+	}
+	if sr.base == "" {
+		return nil, fmt.Errorf("rate table must have a base currency")
+	}
+	sr.rates[sr.base] = big.NewRat(1, 1)
+
+	for c, v := range rates {
+		r, ok := new(big.Rat).SetString(v)
+		if !ok || r.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid rate %q for %s", v, c)
+		}
+		sr.rates[NormalizeCurrency(c)] = r
+	}
+	return sr, nil
+}
+
+func (sr *StaticRates) Rate(from, to string) (*big.Rat, error) {
+	from, to = NormalizeCurrency(from), NormalizeCurrency(to)
+	fromRate, ok := sr.rates[from]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown currency %s", ErrRateNotFound, from)
+	}
+	toRate, ok := sr.rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown currency %s", ErrRateNotFound, to)
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+// rateTable is the JSON representation of a rate table
+type rateTable struct {
+	Base  string                 `json:"base"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// FileRates is a RateProvider backed by a JSON file, like:
+//
+//	{"base": "EUR", "rates": {"THB": 38.5, "USD": "1.08"}}
+//
+// the file is loaded on first use and reloaded whenever it is modified
+type FileRates struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	table   *StaticRates
+}
+
+func NewFileRates(path string) *FileRates {
+	return &FileRates{path: path}
+}
+
+func (fr *FileRates) Rate(from, to string) (*big.Rat, error) {
+	table, err := fr.load()
+	if err != nil {
+		return nil, err
+	}
+	return table.Rate(from, to)
+}
+
+// load returns the current rate table, reading the file again if it changed
+func (fr *FileRates) load() (*StaticRates, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	info, err := os.Stat(fr.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+	if fr.table != nil && info.ModTime().Equal(fr.modTime) {
+		return fr.table, nil
+	}
+
+	data, err := os.ReadFile(fr.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rt rateTable
+	if err := json.Unmarshal(data, &rt); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %s: %w", fr.path, err)
+	}
+
+	rates := make(map[string]string, len(rt.Rates))
+	for c, v := range rt.Rates {
+		rates[c] = v.String()
+	}
+	table, err := NewStaticRates(rt.Base, rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %s: %w", fr.path, err)
+	}
+
+	fr.table, fr.modTime = table, info.ModTime()
+	return table, nil
+}
@@ -0,0 +1,98 @@
+package accounting
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Static_Rates(t *testing.T) {
+	rates, err := NewStaticRates("EUR", map[string]string{"THB": "38.5", "usd": "1.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	scenarios := []struct {
+		name          string
+		from, to      string
+		expected      *big.Rat
+		expectedError error
+	}{
+		{
+			name:     "when from base",
+			from:     "EUR",
+			to:       "THB",
+			expected: big.NewRat(385, 10),
+		},
+		{
+			name:     "when to base",
+			from:     "THB",
+			to:       "EUR",
+			expected: big.NewRat(10, 385),
+		},
+		{
+			name:     "when cross rate",
+			from:     "USD",
+			to:       "THB",
+			expected: big.NewRat(35, 1),
+		},
+		{
+			name:     "when same currency",
+			from:     "thb",
+			to:       "THB",
+			expected: big.NewRat(1, 1),
+		},
+		{
+			name:          "when unknown currency",
+			from:          "GBP",
+			to:            "EUR",
+			expectedError: ErrRateNotFound,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := rates.Rate(s.from, s.to)
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if s.expected != nil && (actual == nil || actual.Cmp(s.expected) != 0) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_File_Rates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base":"EUR","rates":{"THB":38.5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rates := NewFileRates(path)
+	actual, err := rates.Rate("EUR", "THB")
+	if err != nil || actual.Cmp(big.NewRat(385, 10)) != 0 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", "77/2", actual, err)
+	}
+
+	// file changes are picked up without restarting
+	if err := os.WriteFile(path, []byte(`{"base":"EUR","rates":{"THB":"40"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err = rates.Rate("EUR", "THB")
+	if err != nil || actual.Cmp(big.NewRat(40, 1)) != 0 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", "40", actual, err)
+	}
+
+	if _, err := NewFileRates(filepath.Join(t.TempDir(), "missing.json")).Rate("EUR", "THB"); err == nil {
+		t.Errorf("expected error when file is missing")
+	}
+}
@@ -0,0 +1,175 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidReceipt = errors.New("invalid receipt")
+
+// ReceiptItem is a line of a receipt, shared equally between the people who consumed it
+type ReceiptItem struct {
+	Description string   `json:"description,omitempty"`
+	Amount      Money    `json:"amount"`
+	Consumers   []string `json:"consumers"`
+}
+
+// Receipt is an itemized bill, its charges (tax, tip and service charge) are distributed proportionally
+// to each person subtotal
+type Receipt struct {
+	Description   string         `json:"description,omitempty"`
+	PaidBy        []Contribution `json:"paid_by"`
+	Currency      string         `json:"currency,omitempty"`
+	Items         []ReceiptItem  `json:"items"`
+	Tax           Money          `json:"tax,omitzero"`
+	Tip           Money          `json:"tip,omitzero"`
+	ServiceCharge Money          `json:"service_charge,omitzero"`
+}
+
+// ReceiptShare holds how much of a receipt a person owes
+type ReceiptShare struct {
+	Name          string `json:"name"`
+	Subtotal      Money  `json:"subtotal"`
+	Tax           Money  `json:"tax"`
+	Tip           Money  `json:"tip"`
+	ServiceCharge Money  `json:"service_charge"`
+	Total         Money  `json:"total"`
+}
+
+// ReceiptStatement contains each person share of a receipt and the resulting balances
+type ReceiptStatement struct {
+	Shares   []ReceiptShare `json:"shares"`
+	Balances Balances       `json:"balances"`
+}
+
+// Total returns the receipt total, items plus charges, it fails with ErrAmountTooLarge when it is out of range
+func (r Receipt) Total() (Money, error) {
+	total := Money{}
+	var err error
+	for _, m := range []Money{r.Tax, r.Tip, r.ServiceCharge} {
+		if total, err = total.CheckedAdd(m); err != nil {
+			return Money{}, err
+		}
+	}
+	for _, item := range r.Items {
+		if total, err = total.CheckedAdd(item.Amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Shares calculates how much each person owes, people are listed in the order they first appear in the items
+func (r Receipt) Shares() ([]ReceiptShare, error) {
+	if len(r.Items) == 0 {
+		return nil, fmt.Errorf("%w: no items", ErrInvalidReceipt)
+	}
+	for _, charge := range []Money{r.Tax, r.Tip, r.ServiceCharge} {
+		if charge.Sign() < 0 {
+			return nil, fmt.Errorf("%w: charges must not be negative", ErrInvalidReceipt)
+		}
+	}
+
+	// every sum below is at most the total, so it can not overflow once the total is checked
+	total, err := r.Total()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+	}
+
+	index := map[string]int{}
+	var shares []ReceiptShare
+	for i, item := range r.Items {
+		if item.Amount.Sign() < 0 {
+			return nil, fmt.Errorf("%w: item %d amount must not be negative", ErrInvalidReceipt, i)
+		}
+		if len(item.Consumers) == 0 {
+			return nil, fmt.Errorf("%w: item %d has no consumers", ErrInvalidReceipt, i)
+		}
+
+		weights := make([]int64, len(item.Consumers))
+		for j := range weights {
+			weights[j] = 1
+		}
+		parts, err := item.Amount.Allocate(weights)
+		if err != nil {
+			return nil, fmt.Errorf("%w: item %d: %w", ErrInvalidReceipt, i, err)
+		}
+
+		for j, name := range item.Consumers {
+			idx, ok := index[name]
+			if !ok {
+				idx = len(shares)
+				index[name] = idx
+				shares = append(shares, ReceiptShare{Name: name})
+			}
+			shares[idx].Subtotal = shares[idx].Subtotal.Add(parts[j])
+		}
+	}
+
+	// subtotals are the weights to distribute every charge
+	scale := total.scale
+	weights := make([]int64, len(shares))
+	for i, s := range shares {
+		weights[i] = s.Subtotal.Rescale(scale).minor
+	}
+
+	allocateCharge := func(charge Money, assign func(*ReceiptShare, Money)) error {
+		parts := make([]Money, len(shares))
+		if charge.IsZero() {
+			for i := range parts {
+				parts[i] = charge.Rescale(scale)
+			}
+		} else {
+			var err error
+			if parts, err = charge.Rescale(scale).Allocate(weights); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+			}
+		}
+		for i := range shares {
+			assign(&shares[i], parts[i])
+		}
+		return nil
+	}
+
+	if err := allocateCharge(r.Tax, func(s *ReceiptShare, m Money) { s.Tax = m }); err != nil {
+		return nil, err
+	}
+	if err := allocateCharge(r.Tip, func(s *ReceiptShare, m Money) { s.Tip = m }); err != nil {
+		return nil, err
+	}
+	if err := allocateCharge(r.ServiceCharge, func(s *ReceiptShare, m Money) { s.ServiceCharge = m }); err != nil {
+		return nil, err
+	}
+
+	for i, s := range shares {
+		shares[i].Subtotal = s.Subtotal.Rescale(scale)
+		shares[i].Total = s.Subtotal.Add(s.Tax).Add(s.Tip).Add(s.ServiceCharge)
+	}
+	return shares, nil
+}
+
+// Expense converts the receipt into an expense with an exact split of each person share
+func (r Receipt) Expense() (Expense, []ReceiptShare, error) {
+	if r.Currency != "" && !ValidCurrency(r.Currency) {
+		return Expense{}, nil, fmt.Errorf("%w: currency must be a 3 letter code, got %q", ErrInvalidReceipt, r.Currency)
+	}
+	shares, err := r.Shares()
+	if err != nil {
+		return Expense{}, nil, err
+	}
+
+	participants := make([]Participant, len(shares))
+	for i, s := range shares {
+		participants[i] = Participant{Name: s.Name, Amount: s.Total}
+	}
+	// the total was already checked when calculating the shares
+	total, _ := r.Total()
+	return Expense{
+		Description:  r.Description,
+		PaidBy:       r.PaidBy,
+		Total:        total,
+		Currency:     r.Currency,
+		Split:        SplitExact,
+		Participants: participants,
+	}, shares, nil
+}
@@ -0,0 +1,113 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Receipt_Shares(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		input         Receipt
+		expected      []ReceiptShare
+		expectedError error
+	}{
+		{
+			name: "when charges are distributed by subtotal",
+			input: Receipt{
+				Items: []ReceiptItem{
+					{Description: "steak", Amount: money("30"), Consumers: []string{"A"}},
+					{Description: "salad", Amount: money("10"), Consumers: []string{"B"}},
+					{Description: "wine", Amount: money("20"), Consumers: []string{"A", "B"}},
+				},
+				Tax:           money("6"),
+				Tip:           money("10"),
+				ServiceCharge: money("3"),
+			},
+			expected: []ReceiptShare{
+				{Name: "A", Subtotal: money("40"), Tax: money("4"), Tip: money("6.67"), ServiceCharge: money("2"), Total: money("52.67")},
+				{Name: "B", Subtotal: money("20"), Tax: money("2"), Tip: money("3.33"), ServiceCharge: money("1"), Total: money("26.33")},
+			},
+		},
+		{
+			name: "when remainder cents are distributed deterministically",
+			input: Receipt{
+				Items: []ReceiptItem{
+					{Amount: money("10"), Consumers: []string{"A", "B", "C"}},
+				},
+				Tip: money("1"),
+			},
+			expected: []ReceiptShare{
+				{Name: "A", Subtotal: money("3.34"), Tax: money("0"), Tip: money("0.34"), ServiceCharge: money("0"), Total: money("3.68")},
+				{Name: "B", Subtotal: money("3.33"), Tax: money("0"), Tip: money("0.33"), ServiceCharge: money("0"), Total: money("3.66")},
+				{Name: "C", Subtotal: money("3.33"), Tax: money("0"), Tip: money("0.33"), ServiceCharge: money("0"), Total: money("3.66")},
+			},
+		},
+		{
+			name: "when item has no consumers",
+			input: Receipt{
+				Items: []ReceiptItem{{Amount: money("10")}},
+			},
+			expectedError: ErrInvalidReceipt,
+		},
+		{
+			name: "when charges without subtotal",
+			input: Receipt{
+				Items: []ReceiptItem{{Amount: money("0"), Consumers: []string{"A"}}},
+				Tax:   money("1"),
+			},
+			expectedError: ErrInvalidReceipt,
+		},
+		{
+			name:          "when no items",
+			input:         Receipt{},
+			expectedError: ErrInvalidReceipt,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.input.Shares()
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Service_Calculate_Receipt(t *testing.T) {
+	receipt := Receipt{
+		PaidBy: []Contribution{{Name: "A", Amount: money("66")}},
+		Items: []ReceiptItem{
+			{Amount: money("40"), Consumers: []string{"A"}},
+			{Amount: money("20"), Consumers: []string{"B"}},
+		},
+		Tax: money("6"),
+	}
+
+	actual, err := NewService().CalculateReceipt(context.Background(), receipt)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := Balances{
+		{Name: "A", Amount: money("22")},
+		{Name: "B", Amount: money("-22")},
+	}
+	if !reflect.DeepEqual(expected, actual.Balances) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, actual.Balances)
+	}
+
+	receipt.PaidBy = []Contribution{{Name: "A", Amount: money("60")}}
+	if _, err := NewService().CalculateReceipt(context.Background(), receipt); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrInvalidSplit, err)
+	}
+
+	_, err = NewService(WithMaxGroupSize(1)).CalculateReceipt(context.Background(), receipt)
+	assertFieldErrors(t, []FieldError{{Index: -1, Field: "names", Message: "must not have more than 1 distinct people, got 2"}}, err)
+}
@@ -0,0 +1,21 @@
+package accounting
+
+import "time"
+
+// MinimizeObservation describes a finished minimization
+type MinimizeObservation struct {
+	// GroupSize is the number of balances settled
+	GroupSize    int
+	Transactions int
+	Objective    Objective
+	Duration     time.Duration
+}
+
+// Recorder receives telemetry about the service operations, e.g. to expose metrics
+type Recorder interface {
+	ObserveMinimize(MinimizeObservation)
+}
+
+type noopRecorder struct{}
+
+func (noopRecorder) ObserveMinimize(MinimizeObservation) {}
@@ -0,0 +1,116 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrVersionConflict     = errors.New("transaction version conflict")
+)
+
+// Action is the change a revision made to a transaction
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+// Revision is a version of a group transaction, with who changed it and when
+// ledgers only append revisions, so the history of every transaction stays inspectable
+type Revision struct {
+	Transaction
+	Action Action    `json:"action"`
+	Actor  string    `json:"actor,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// TransactionPatch changes only the fields that are set
+type TransactionPatch struct {
+	From     *string `json:"from,omitempty"`
+	To       *string `json:"to,omitempty"`
+	Amount   *Money  `json:"amount,omitempty"`
+	Currency *string `json:"currency,omitempty"`
+	// Contributors replace the contributors, an empty list removes them
+	Contributors *[]Contribution `json:"contributors,omitempty"`
+	Description  *string         `json:"description,omitempty"`
+	OccurredAt   *time.Time      `json:"occurred_at,omitempty"`
+}
+
+// apply returns the transaction with the patched fields
+func (p TransactionPatch) apply(t Transaction) Transaction {
+	if p.From != nil {
+		t.From = *p.From
+	}
+	if p.To != nil {
+		t.To = *p.To
+	}
+	if p.Amount != nil {
+		t.Amount = *p.Amount
+	}
+	if p.Currency != nil {
+		t.Currency = *p.Currency
+	}
+	if p.Contributors != nil {
+		t.Contributors = *p.Contributors
+	}
+	if p.Description != nil {
+		t.Description = *p.Description
+	}
+	if p.OccurredAt != nil {
+		t.OccurredAt = *p.OccurredAt
+	}
+	return t
+}
+
+// CurrentTransactions folds revisions into the transactions not deleted, in the order they were created
+// revisions without id, from ledgers written before transactions had one, are kept as they are
+func CurrentTransactions(revisions []Revision) Transactions {
+	transactions := Transactions{}
+	positions := map[string]int{}
+	deleted := map[string]bool{}
+	for _, r := range revisions {
+		i, ok := positions[r.ID]
+		switch {
+		case r.ID == "" || !ok:
+			positions[r.ID] = len(transactions)
+			transactions = append(transactions, r.Transaction)
+		case r.Action == ActionDeleted:
+			deleted[r.ID] = true
+		default:
+			transactions[i] = r.Transaction
+		}
+	}
+	return slices.DeleteFunc(transactions, func(t Transaction) bool {
+		return deleted[t.ID]
+	})
+}
+
+// transactionHistory returns the revisions of a transaction, oldest first
+func transactionHistory(revisions []Revision, id string) []Revision {
+	history := []Revision{}
+	for _, r := range revisions {
+		if r.ID == id && id != "" {
+			history = append(history, r)
+		}
+	}
+	return history
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying who is making changes, recorded in the revisions
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns who is making changes, empty when unknown
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -1,16 +1,304 @@
 package accounting
 
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrInvalidOption = errors.New("invalid option")
+
 // Service just represents a way to access calculate and minimize operations
-type Service struct{}
+// and to keep the ledger of groups
+type Service struct {
+	rates         RateProvider
+	ledger        LedgerRepository
+	exactLimit    int
+	maxGroupSize  int
+	zeroTolerance Money
+	recorder      Recorder
+	now           func() time.Time
+	// ledgerMu serializes changes to group ledgers, so versions stay sequential and settlements are not paid twice
+	ledgerMu sync.Mutex
+}
+
+// ServiceOption configures a Service
+type ServiceOption func(*Service)
+
+// WithRateProvider sets the provider used to convert amounts between currencies
+func WithRateProvider(rates RateProvider) ServiceOption {
+	return func(s *Service) {
+		s.rates = rates
+	}
+}
+
+// WithLedger sets the repository groups and their transactions are stored in, memory is used by default
+func WithLedger(ledger LedgerRepository) ServiceOption {
+	return func(s *Service) {
+		s.ledger = ledger
+	}
+}
+
+// WithExactLimit sets up to how many non-zero balances the exact minimizer is used, above it greedy is used
+// it is capped at MaxExactLimit
+func WithExactLimit(limit int) ServiceOption {
+	return func(s *Service) {
+		s.exactLimit = min(limit, MaxExactLimit)
+	}
+}
+
+// WithMaxGroupSize sets the maximum number of distinct people accepted in a single operation, 0 means no limit
+func WithMaxGroupSize(size int) ServiceOption {
+	return func(s *Service) {
+		s.maxGroupSize = size
+	}
+}
+
+// WithZeroSumTolerance sets how far from zero the sum of balances can be and still be minimized
+func WithZeroSumTolerance(tolerance Money) ServiceOption {
+	return func(s *Service) {
+		s.zeroTolerance = tolerance.Abs()
+	}
+}
+
+// WithRecorder sets where the operations telemetry is sent, nothing is recorded by default
+func WithRecorder(recorder Recorder) ServiceOption {
+	return func(s *Service) {
+		s.recorder = recorder
+	}
+}
+
+func NewService(opts ...ServiceOption) *Service {
+	s := &Service{
+		ledger:        NewMemoryLedger(),
+		exactLimit:    DefaultExactLimit,
+		maxGroupSize:  DefaultMaxGroupSize,
+		zeroTolerance: DefaultZeroSumTolerance,
+		recorder:      noopRecorder{},
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CheckStorage reports if the ledger repository can serve requests, repositories that can not tell are healthy
+func (s *Service) CheckStorage(ctx context.Context) error {
+	if checker, ok := s.ledger.(HealthChecker); ok {
+		return checker.CheckHealth(ctx)
+	}
+	return nil
+}
+
+// Option configures a single Calculate or Minimize operation
+type Option func(*options)
+
+type options struct {
+	currency    string
+	algorithm   Algorithm
+	objective   Objective
+	constraints SettlementConstraints
+	asOf        time.Time
+}
+
+// WithSettlementCurrency converts every amount into the given currency before operating
+func WithSettlementCurrency(currency string) Option {
+	return func(o *options) {
+		o.currency = currency
+	}
+}
 
-func NewService() *Service {
-	return &Service{}
+// WithAlgorithm selects how transactions are minimized, AlgorithmAuto by default
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = algorithm
+	}
 }
 
-func (s *Service) Calculate(transactions Transactions) Balances {
-	return calculateBalance(transactions)
+// WithObjective selects what the settlement optimizes for, ObjectiveCount by default
+func WithObjective(objective Objective) Option {
+	return func(o *options) {
+		o.objective = objective
+	}
 }
 
-func (s *Service) Minimize(balances Balances) Statement {
-	return minimizeTransactions(balances)
+// WithConstraints settles balances respecting the constraints, which takes precedence over the algorithm
+func WithConstraints(constraints SettlementConstraints) Option {
+	return func(o *options) {
+		o.constraints = constraints
+	}
+}
+
+// WithAsOf only takes into account what occurred up to the given time, inclusive, undated transactions included
+// it has no effect on operations over balances, which are already calculated
+func WithAsOf(asOf time.Time) Option {
+	return func(o *options) {
+		o.asOf = asOf
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{algorithm: AlgorithmAuto}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Calculate calculates balances for the transactions, converted into a single currency
+// it fails with a ValidationError when any transaction is invalid
+func (s *Service) Calculate(ctx context.Context, transactions Transactions, opts ...Option) (Balances, error) {
+	if err := ValidateTransactions(transactions, s.maxGroupSize); err != nil {
+		return nil, err
+	}
+	return s.calculate(transactions, opts...)
+}
+
+// calculate same as Calculate without validating the transactions
+func (s *Service) calculate(transactions Transactions, opts ...Option) (Balances, error) {
+	o := newOptions(opts)
+	if !o.asOf.IsZero() {
+		transactions = slices.DeleteFunc(slices.Clone(transactions), func(t Transaction) bool {
+			return t.OccurredAt.After(o.asOf)
+		})
+	}
+
+	currencies := make([]string, len(transactions))
+	for i, t := range transactions {
+		currencies[i] = t.Currency
+	}
+	currency, err := settlementCurrency(o.currency, currencies...)
+	if err != nil {
+		return nil, err
+	}
+
+	conv := newConverter(s.rates, currency)
+	converted := make(Transactions, len(transactions))
+	for i, t := range transactions {
+		if t.Amount, err = conv.convert(t.Amount, t.Currency); err != nil {
+			return nil, err
+		}
+		// contributions are converted one by one, so balances still sum to zero after rounding
+		t.Contributors = slices.Clone(t.Contributors)
+		for j, c := range t.Contributors {
+			if t.Contributors[j].Amount, err = conv.convert(c.Amount, t.Currency); err != nil {
+				return nil, err
+			}
+		}
+		t.Currency = currency
+		converted[i] = t
+	}
+
+	balances, err := calculateBalance(converted)
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		balances[i].Currency = currency
+	}
+	return balances, nil
+}
+
+// Minimize minimizes the transactions needed to settle the balances, converted into a single currency
+// it fails with a ValidationError when any balance is invalid or they do not sum to zero
+func (s *Service) Minimize(ctx context.Context, balances Balances, opts ...Option) (Statement, error) {
+	if err := ValidateBalances(balances, s.maxGroupSize); err != nil {
+		return Statement{}, err
+	}
+
+	o := newOptions(opts)
+
+	currencies := make([]string, len(balances))
+	for i, b := range balances {
+		currencies[i] = b.Currency
+	}
+	currency, err := settlementCurrency(o.currency, currencies...)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	conv := newConverter(s.rates, currency)
+	converted := make(Balances, len(balances))
+	for i, b := range balances {
+		if b.Amount, err = conv.convert(b.Amount, b.Currency); err != nil {
+			return Statement{}, err
+		}
+		b.Currency = currency
+		converted[i] = b
+	}
+
+	// each converted amount is rounded, which can move the sum up to half minor unit per balance
+	tolerance := s.zeroTolerance
+	if len(conv.used) > 0 {
+		tolerance = tolerance.Add(NewMoney(5*int64(len(balances)), CurrencyScale(currency)+1))
+	}
+	if err := validateZeroSum(converted, tolerance); err != nil {
+		return Statement{}, err
+	}
+
+	start := s.now()
+	statement, err := settle(converted, o, s.exactLimit)
+	if err != nil {
+		return Statement{}, err
+	}
+	// settle already validated the objective
+	objective, _ := ParseObjective(string(o.objective))
+	observation := MinimizeObservation{
+		GroupSize:    len(balances),
+		Transactions: len(statement.Transactions),
+		Objective:    objective,
+		Duration:     s.now().Sub(start),
+	}
+	s.recorder.ObserveMinimize(observation)
+	slog.DebugContext(ctx, "balances minimized",
+		"group_size", observation.GroupSize,
+		"transactions", observation.Transactions,
+		"objective", observation.Objective,
+		"duration", observation.Duration,
+	)
+	for i := range statement.Transactions {
+		statement.Transactions[i].Currency = currency
+	}
+	statement.Currency = currency
+	statement.Rates = conv.usedRates()
+	return statement, nil
+}
+
+// CalculateExpenses expands the expenses into transactions and calculates their balances
+func (s *Service) CalculateExpenses(ctx context.Context, expenses Expenses, opts ...Option) (Balances, error) {
+	if err := validateExpensesGroupSize(expenses, s.maxGroupSize); err != nil {
+		return nil, err
+	}
+	transactions, err := ExpandExpenses(expenses)
+	if err != nil {
+		return nil, err
+	}
+	// expenses have their own validation, their transactions can have zero shares
+	return s.calculate(transactions, opts...)
+}
+
+// CalculateReceipt splits an itemized receipt between the people who consumed it and calculates their balances
+func (s *Service) CalculateReceipt(ctx context.Context, receipt Receipt, opts ...Option) (ReceiptStatement, error) {
+	if err := validateReceiptGroupSize(receipt, s.maxGroupSize); err != nil {
+		return ReceiptStatement{}, err
+	}
+	expense, shares, err := receipt.Expense()
+	if err != nil {
+		return ReceiptStatement{}, err
+	}
+
+	transactions, err := expense.Transactions()
+	if err != nil {
+		return ReceiptStatement{}, err
+	}
+
+	balances, err := s.calculate(transactions, opts...)
+	if err != nil {
+		return ReceiptStatement{}, err
+	}
+	return ReceiptStatement{Shares: shares, Balances: balances}, nil
 }
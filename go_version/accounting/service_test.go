@@ -0,0 +1,228 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Service_Calculate_Currencies(t *testing.T) {
+	rates, _ := NewStaticRates("EUR", map[string]string{"THB": "40", "JPY": "160"})
+	service := NewService(WithRateProvider(rates))
+
+	scenarios := []struct {
+		name          string
+		input         Transactions
+		opts          []Option
+		expected      Balances
+		expectedError error
+	}{
+		{
+			name: "when single currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("40"), Currency: "THB"},
+			},
+			expected: Balances{
+				{Name: "A", Amount: money("40"), Currency: "THB"},
+				{Name: "B", Amount: money("-40"), Currency: "THB"},
+			},
+		},
+		{
+			name: "when mixed currencies without settlement currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("40"), Currency: "THB"},
+				{From: "B", To: "A", Amount: money("1"), Currency: "EUR"},
+			},
+			expectedError: ErrMixedCurrencies,
+		},
+		{
+			name: "when mixed currencies with settlement currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("400"), Currency: "THB"},
+				{From: "B", To: "A", Amount: money("1"), Currency: "eur"},
+			},
+			opts: []Option{WithSettlementCurrency("EUR")},
+			expected: Balances{
+				{Name: "A", Amount: money("9"), Currency: "EUR"},
+				{Name: "B", Amount: money("-9"), Currency: "EUR"},
+			},
+		},
+		{
+			name: "when converting to currency without decimals",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10.01"), Currency: "EUR"},
+			},
+			opts: []Option{WithSettlementCurrency("JPY")},
+			expected: Balances{
+				{Name: "A", Amount: NewMoney(1602, 0), Currency: "JPY"},
+				{Name: "B", Amount: NewMoney(-1602, 0), Currency: "JPY"},
+			},
+		},
+		{
+			name: "when converting several payers",
+			input: Transactions{
+				{To: "C", Amount: money("20.02"), Currency: "EUR", Contributors: []Contribution{{Name: "A", Amount: money("10.01")}, {Name: "B", Amount: money("10.01")}}},
+			},
+			opts: []Option{WithSettlementCurrency("JPY")},
+			expected: Balances{
+				{Name: "A", Amount: NewMoney(1602, 0), Currency: "JPY"},
+				{Name: "B", Amount: NewMoney(1602, 0), Currency: "JPY"},
+				{Name: "C", Amount: NewMoney(-3204, 0), Currency: "JPY"},
+			},
+		},
+		{
+			name: "when invalid settlement currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10"), Currency: "EUR"},
+			},
+			opts:          []Option{WithSettlementCurrency("EURO")},
+			expectedError: ErrInvalidOption,
+		},
+		{
+			name: "when balances are out of range",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("1000000000000"), Currency: "EUR"},
+				{From: "A", To: "B", Amount: money("1000000000000"), Currency: "EUR"},
+			},
+			expectedError: ErrAmountTooLarge,
+		},
+		{
+			name: "when unknown currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10"), Currency: "GBP"},
+			},
+			opts:          []Option{WithSettlementCurrency("EUR")},
+			expectedError: ErrRateNotFound,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := service.Calculate(context.Background(), s.input, s.opts...)
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) && (s.expected != nil || actual != nil) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Service_Calculate_As_Of(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	transactions := Transactions{
+		{From: "A", To: "B", Amount: money("40"), OccurredAt: day(1)},
+		{From: "B", To: "C", Amount: money("30")},
+		{From: "C", To: "A", Amount: money("300"), Description: "hotel", OccurredAt: day(3)},
+	}
+
+	scenarios := []struct {
+		name     string
+		asOf     time.Time
+		expected Balances
+	}{
+		{
+			name: "should take everything without as of",
+			expected: Balances{
+				{Name: "A", Amount: money("-260")},
+				{Name: "B", Amount: money("-10")},
+				{Name: "C", Amount: money("270")},
+			},
+		},
+		{
+			name: "should leave out what occurred later",
+			asOf: day(2),
+			expected: Balances{
+				{Name: "A", Amount: money("40")},
+				{Name: "B", Amount: money("-10")},
+				{Name: "C", Amount: money("-30")},
+			},
+		},
+		{
+			name: "should include what occurred at the same instant",
+			asOf: day(3),
+			expected: Balances{
+				{Name: "A", Amount: money("-260")},
+				{Name: "B", Amount: money("-10")},
+				{Name: "C", Amount: money("270")},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := NewService().Calculate(context.Background(), transactions, WithAsOf(s.asOf))
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Service_Minimize_Currencies(t *testing.T) {
+	rates, _ := NewStaticRates("EUR", map[string]string{"THB": "40"})
+	service := NewService(WithRateProvider(rates))
+
+	actual, err := service.Minimize(context.Background(), Balances{
+		{Name: "A", Amount: money("1200"), Currency: "THB"},
+		{Name: "B", Amount: money("-30"), Currency: "EUR"},
+	}, WithSettlementCurrency("EUR"))
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := Statement{
+		UpdatedBalances: Balances{
+			{Name: "B", Amount: money("0"), Currency: "EUR"},
+			{Name: "A", Amount: money("0"), Currency: "EUR"},
+		},
+		Transactions: Transactions{
+			{From: "B", To: "A", Amount: money("30"), Currency: "EUR"},
+		},
+		Currency: "EUR",
+		Rates:    []Rate{{From: "THB", To: "EUR", Rate: "0.02500000"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, actual)
+	}
+}
+
+type recorderStub struct {
+	observations []MinimizeObservation
+}
+
+func (rs *recorderStub) ObserveMinimize(o MinimizeObservation) {
+	rs.observations = append(rs.observations, o)
+}
+
+func Test_Service_Minimize_Recorder(t *testing.T) {
+	recorder := &recorderStub{}
+	service := NewService(WithRecorder(recorder))
+	service.now = func() time.Time {
+		return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	_, _ = service.Minimize(context.Background(), Balances{{Name: "A", Amount: money("10")}, {Name: "B", Amount: money("-10")}})
+	_, _ = service.Minimize(context.Background(), Balances{{Name: "A", Amount: money("10")}}, WithObjective(ObjectivePayers))
+	_, _ = service.Minimize(context.Background(),
+		Balances{{Name: "A", Amount: money("10")}, {Name: "B", Amount: money("-5")}, {Name: "C", Amount: money("-5")}},
+		WithObjective(ObjectiveVolume),
+	)
+
+	expected := []MinimizeObservation{
+		{GroupSize: 2, Transactions: 1, Objective: ObjectiveCount},
+		{GroupSize: 3, Transactions: 2, Objective: ObjectiveVolume},
+	}
+	if !reflect.DeepEqual(expected, recorder.observations) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, recorder.observations)
+	}
+}
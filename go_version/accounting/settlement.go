@@ -0,0 +1,130 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSettlement = errors.New("invalid settlement")
+
+// Settlement is a payment made to pay down a debt of a statement, unlike a transaction it is not an expense
+// it moves balances the same way as a transaction from the payer to the receiver
+type Settlement struct {
+	ID       string    `json:"id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Amount   Money     `json:"amount"`
+	Currency string    `json:"currency,omitempty"`
+	PaidAt   time.Time `json:"paid_at"`
+	// VoidedAt is set when the settlement is cancelled, ledgers then append the voided copy
+	VoidedAt time.Time `json:"voided_at,omitzero"`
+}
+
+// Settlements type alias for Settlement slice
+type Settlements = []Settlement
+
+// transaction returns the settlement as the transaction it offsets in the balances
+func (s Settlement) transaction() Transaction {
+	return Transaction{From: s.From, To: s.To, Amount: s.Amount, Currency: s.Currency, OccurredAt: s.PaidAt}
+}
+
+// validate checks the settlement is between two different members, a zero amount means the whole outstanding amount
+func (s Settlement) validate(group Group) error {
+	if strings.TrimSpace(s.From) == "" || strings.TrimSpace(s.To) == "" {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidSettlement)
+	}
+	if s.From == s.To {
+		return fmt.Errorf("%w: %s can not pay themselves", ErrInvalidSettlement, s.From)
+	}
+	for _, name := range []string{s.From, s.To} {
+		if !group.HasMember(name) {
+			return fmt.Errorf("%w: %s is not a member of %s", ErrUnknownMember, name, group.Name)
+		}
+	}
+	if s.Amount.Sign() < 0 {
+		return fmt.Errorf("%w: amount must not be negative", ErrInvalidSettlement)
+	}
+	if s.Currency != "" && !ValidCurrency(s.Currency) {
+		return fmt.Errorf("%w: currency must be a 3 letter code, got %q", ErrInvalidSettlement, s.Currency)
+	}
+	return nil
+}
+
+// settle applies the settlement against the outstanding transactions of a statement, summing every one between
+// the same pair in the same currency, e.g. when max transfer limits split a debt
+// the amount defaults to the whole outstanding amount and can not exceed it
+func (s Settlement) settle(statement Statement) (Settlement, error) {
+	var pairs Transactions
+	for _, t := range statement.Transactions {
+		if t.From == s.From && t.To == s.To {
+			pairs = append(pairs, t)
+		}
+	}
+	if len(pairs) == 0 {
+		return Settlement{}, fmt.Errorf("%w: %s owes nothing to %s", ErrInvalidSettlement, s.From, s.To)
+	}
+
+	currency := pairs[0].Currency
+	if s.Currency != "" {
+		if !slices.ContainsFunc(pairs, func(t Transaction) bool { return t.Currency == NormalizeCurrency(s.Currency) }) {
+			return Settlement{}, fmt.Errorf("%w: currency must be %s, as in the statement", ErrInvalidSettlement, currency)
+		}
+		currency = NormalizeCurrency(s.Currency)
+	}
+	outstanding := Money{}
+	for _, t := range pairs {
+		if t.Currency == currency {
+			outstanding = outstanding.Add(t.Amount)
+		}
+	}
+
+	s.Currency = currency
+	if s.Amount.IsZero() {
+		s.Amount = outstanding
+	}
+	if s.Amount.Cmp(outstanding) > 0 {
+		return Settlement{}, fmt.Errorf("%w: %s only owes %s to %s", ErrInvalidSettlement, s.From, outstanding, s.To)
+	}
+	return s, nil
+}
+
+// currentSettlements folds the settlements appended to a ledger into one per id, voided ones included
+func currentSettlements(settlements Settlements) Settlements {
+	current := Settlements{}
+	positions := map[string]int{}
+	for _, st := range settlements {
+		if i, ok := positions[st.ID]; ok && st.ID != "" {
+			current[i] = st
+			continue
+		}
+		positions[st.ID] = len(current)
+		current = append(current, st)
+	}
+	return current
+}
+
+// activeSettlements returns the settlements not voided
+func activeSettlements(settlements Settlements) Settlements {
+	return slices.DeleteFunc(currentSettlements(settlements), func(st Settlement) bool {
+		return !st.VoidedAt.IsZero()
+	})
+}
+
+// settledTransactions sums the settlements paid between each pair of members, in the order they were first paid
+func settledTransactions(settlements Settlements) Transactions {
+	settled := Transactions{}
+	for _, s := range settlements {
+		i := slices.IndexFunc(settled, func(t Transaction) bool {
+			return t.From == s.From && t.To == s.To && t.Currency == s.Currency
+		})
+		if i < 0 {
+			settled = append(settled, Transaction{From: s.From, To: s.To, Amount: s.Amount, Currency: s.Currency})
+			continue
+		}
+		settled[i].Amount = settled[i].Amount.Add(s.Amount)
+	}
+	return settled
+}
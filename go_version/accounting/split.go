@@ -0,0 +1,120 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSplit = errors.New("invalid split")
+
+// SplitKind identifies how an expense total is shared between participants
+type SplitKind string
+
+const (
+	SplitEqual      SplitKind = "equal"
+	SplitExact      SplitKind = "exact"
+	SplitPercentage SplitKind = "percentage"
+	SplitShares     SplitKind = "shares"
+)
+
+// SplitStrategy splits an expense total into the share owed by each participant
+// the returned shares follow the participants order and always sum to total
+type SplitStrategy interface {
+	Split(total Money, participants []Participant) ([]Money, error)
+}
+
+// splitStrategies all known strategies by kind
+var splitStrategies = map[SplitKind]SplitStrategy{
+	SplitEqual:      EqualSplit{},
+	SplitExact:      ExactSplit{},
+	SplitPercentage: PercentageSplit{},
+	SplitShares:     SharesSplit{},
+}
+
+// SplitStrategyFor returns the strategy for the kind, an empty kind means an equal split
+func SplitStrategyFor(kind SplitKind) (SplitStrategy, error) {
+	if kind == "" {
+		kind = SplitEqual
+	}
+	if s, ok := splitStrategies[kind]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("%w: unknown split %q", ErrInvalidSplit, kind)
+}
+
+// EqualSplit everyone owes the same, remaining minor units go to the first participants
+type EqualSplit struct{}
+
+func (EqualSplit) Split(total Money, participants []Participant) ([]Money, error) {
+	weights := make([]int64, len(participants))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return allocateSplit(total, weights)
+}
+
+// ExactSplit each participant owes the informed amount, which must sum to the total
+type ExactSplit struct{}
+
+func (ExactSplit) Split(total Money, participants []Participant) ([]Money, error) {
+	shares := make([]Money, len(participants))
+	sum := total.Zero()
+	for i, p := range participants {
+		if p.Amount.Sign() < 0 {
+			return nil, fmt.Errorf("%w: negative amount for %s", ErrInvalidSplit, p.Name)
+		}
+		shares[i] = p.Amount
+		var err error
+		if sum, err = sum.CheckedAdd(p.Amount); err != nil {
+			return nil, fmt.Errorf("%w: amounts: %w", ErrInvalidSplit, err)
+		}
+	}
+	if !sum.Equal(total) {
+		return nil, fmt.Errorf("%w: amounts sum to %s, expected %s", ErrInvalidSplit, sum, total)
+	}
+	return shares, nil
+}
+
+// PercentageSplit each participant owes a percentage of the total, which must sum to 100%
+type PercentageSplit struct{}
+
+func (PercentageSplit) Split(total Money, participants []Participant) ([]Money, error) {
+	weights := make([]int64, len(participants))
+	var sum Percent
+	for i, p := range participants {
+		if p.Percent < 0 {
+			return nil, fmt.Errorf("%w: negative percentage for %s", ErrInvalidSplit, p.Name)
+		}
+		weights[i] = int64(p.Percent)
+		sum += p.Percent
+	}
+	if sum != HundredPercent {
+		return nil, fmt.Errorf("%w: percentages sum to %s, expected %s", ErrInvalidSplit, sum, HundredPercent)
+	}
+	return allocateSplit(total, weights)
+}
+
+// SharesSplit each participant owes proportionally to its number of shares, e.g. 2 shares owes twice 1 share
+type SharesSplit struct{}
+
+func (SharesSplit) Split(total Money, participants []Participant) ([]Money, error) {
+	weights := make([]int64, len(participants))
+	for i, p := range participants {
+		if p.Shares < 0 {
+			return nil, fmt.Errorf("%w: negative shares for %s", ErrInvalidSplit, p.Name)
+		}
+		weights[i] = p.Shares
+	}
+	return allocateSplit(total, weights)
+}
+
+func allocateSplit(total Money, weights []int64) ([]Money, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("%w: no participants", ErrInvalidSplit)
+	}
+	shares, err := total.Allocate(weights)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSplit, err)
+	}
+	return shares, nil
+}
@@ -0,0 +1,168 @@
+package accounting
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Allocate(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		amount   Money
+		weights  []int64
+		expected []Money
+	}{
+		{
+			name:     "when exact division",
+			amount:   money("30"),
+			weights:  []int64{1, 1, 1},
+			expected: []Money{money("10"), money("10"), money("10")},
+		},
+		{
+			name:     "when remainder goes to first ones",
+			amount:   money("10"),
+			weights:  []int64{1, 1, 1},
+			expected: []Money{money("3.34"), money("3.33"), money("3.33")},
+		},
+		{
+			name:     "when remainder goes to largest fraction",
+			amount:   money("0.05"),
+			weights:  []int64{1, 3},
+			expected: []Money{money("0.01"), money("0.04")},
+		},
+		{
+			name:     "when negative amount",
+			amount:   money("-10"),
+			weights:  []int64{1, 1, 1},
+			expected: []Money{money("-3.34"), money("-3.33"), money("-3.33")},
+		},
+		{
+			name:     "when zero weight",
+			amount:   money("10"),
+			weights:  []int64{0, 1},
+			expected: []Money{money("0"), money("10")},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := s.amount.Allocate(s.weights)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+
+	if _, err := money("10").Allocate([]int64{0, 0}); err == nil {
+		t.Errorf("expected error when all weights are zero")
+	}
+}
+
+func Test_Split_Strategies(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		kind          SplitKind
+		total         Money
+		participants  []Participant
+		expected      []Money
+		expectedError error
+	}{
+		{
+			name:         "when equal",
+			kind:         SplitEqual,
+			total:        money("100"),
+			participants: []Participant{{Name: "A"}, {Name: "B"}, {Name: "C"}},
+			expected:     []Money{money("33.34"), money("33.33"), money("33.33")},
+		},
+		{
+			name:         "when no kind defaults to equal",
+			total:        money("10"),
+			participants: []Participant{{Name: "A"}, {Name: "B"}},
+			expected:     []Money{money("5"), money("5")},
+		},
+		{
+			name:  "when exact",
+			kind:  SplitExact,
+			total: money("100"),
+			participants: []Participant{
+				{Name: "A", Amount: money("70")},
+				{Name: "B", Amount: money("30")},
+			},
+			expected: []Money{money("70"), money("30")},
+		},
+		{
+			name:  "when exact does not sum to total",
+			kind:  SplitExact,
+			total: money("100"),
+			participants: []Participant{
+				{Name: "A", Amount: money("70")},
+				{Name: "B", Amount: money("20")},
+			},
+			expectedError: ErrInvalidSplit,
+		},
+		{
+			name:  "when percentage",
+			kind:  SplitPercentage,
+			total: money("100"),
+			participants: []Participant{
+				{Name: "A", Percent: 3333},
+				{Name: "B", Percent: 3333},
+				{Name: "C", Percent: 3334},
+			},
+			expected: []Money{money("33.33"), money("33.33"), money("33.34")},
+		},
+		{
+			name:  "when percentage does not sum to 100",
+			kind:  SplitPercentage,
+			total: money("100"),
+			participants: []Participant{
+				{Name: "A", Percent: 5000},
+				{Name: "B", Percent: 4000},
+			},
+			expectedError: ErrInvalidSplit,
+		},
+		{
+			name:  "when shares",
+			kind:  SplitShares,
+			total: money("90"),
+			participants: []Participant{
+				{Name: "A", Shares: 2},
+				{Name: "B", Shares: 1},
+			},
+			expected: []Money{money("60"), money("30")},
+		},
+		{
+			name:          "when unknown kind",
+			kind:          "random",
+			total:         money("90"),
+			participants:  []Participant{{Name: "A"}},
+			expectedError: ErrInvalidSplit,
+		},
+		{
+			name:          "when no participants",
+			kind:          SplitEqual,
+			total:         money("90"),
+			expectedError: ErrInvalidSplit,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var actual []Money
+			strategy, err := SplitStrategyFor(s.kind)
+			if err == nil {
+				actual, err = strategy.Split(s.total, s.participants)
+			}
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
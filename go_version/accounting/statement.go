@@ -2,6 +2,11 @@ package accounting
 
 // Statement contains information of updated balances and transactions after minimize operation
 type Statement struct {
-	UpdatedBalances Balances     `json:"updated_balances"`
-	Transactions    Transactions `json:"transactions"`
+	UpdatedBalances Balances `json:"updated_balances"`
+	// Transactions are the outstanding payments still needed to settle the balances
+	Transactions Transactions `json:"transactions"`
+	// Settled sums the recorded settlements between each pair of members, only for groups
+	Settled  Transactions `json:"settled,omitempty"`
+	Currency string       `json:"currency,omitempty"`
+	Rates    []Rate       `json:"rates,omitempty"`
 }
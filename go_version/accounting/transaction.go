@@ -1,11 +1,58 @@
 package accounting
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTime = errors.New("invalid time")
+
 // Transaction holds the current amount for a person
 type Transaction struct {
-	From   string  `json:"from"`
-	To     string  `json:"to"`
-	Amount float64 `json:"amount"`
+	// ID and Version identify group transactions, the version increases on every change
+	ID       string `json:"id,omitempty"`
+	Version  int    `json:"version,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   Money  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+	// Contributors replace From when several people paid, their amounts sum to Amount
+	Contributors []Contribution `json:"contributors,omitempty"`
+	// Description is free text, e.g. what was paid for, it does not affect calculations
+	Description string `json:"description,omitempty"`
+	// OccurredAt is when the expense happened, undated transactions are taken into account at any point in time
+	OccurredAt time.Time `json:"occurred_at,omitzero"`
 }
 
 // Transactions type alias for Transaction slice
 type Transactions = []Transaction
+
+// Payments returns the transaction as one transaction per payer, itself when it has no contributors
+func (t Transaction) Payments() Transactions {
+	if len(t.Contributors) == 0 {
+		return Transactions{t}
+	}
+	payments := make(Transactions, len(t.Contributors))
+	for i, c := range t.Contributors {
+		p := t
+		p.From, p.Amount, p.Contributors = c.Name, c.Amount, nil
+		payments[i] = p
+	}
+	return payments
+}
+
+// timeLayouts are the accepted formats of points in time
+var timeLayouts = []string{time.RFC3339, time.DateOnly}
+
+// ParseTime parses an RFC 3339 time or a `2006-01-02` date, which is midnight UTC
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q is not a date like 2006-01-02 or a time like 2006-01-02T15:04:05Z", ErrInvalidTime, s)
+}
@@ -0,0 +1,210 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultMaxGroupSize maximum number of distinct people accepted in a single operation
+	DefaultMaxGroupSize = 1000
+)
+
+// DefaultZeroSumTolerance how far from zero the sum of balances can be and still be settled
+var DefaultZeroSumTolerance = NewMoney(1, 2)
+
+var ErrValidation = errors.New("validation failed")
+
+// FieldError points to an invalid field of an input list item, Index is -1 when it is about the whole list
+type FieldError struct {
+	Index   int    `json:"index"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// String formats the error as "[index].field message", leaving the index out for list level errors
+func (fe FieldError) String() string {
+	if fe.Index < 0 {
+		return fmt.Sprintf("%s %s", fe.Field, fe.Message)
+	}
+	return fmt.Sprintf("[%d].%s %s", fe.Index, fe.Field, fe.Message)
+}
+
+// ValidationError holds every invalid field found in an input, it matches ErrValidation with errors.Is
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (ve *ValidationError) Error() string {
+	msgs := make([]string, len(ve.Errors))
+	for i, fe := range ve.Errors {
+		msgs[i] = fe.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(msgs, ", "))
+}
+
+func (ve *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// validator collects field errors
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(index int, field, message string) {
+	v.errs = append(v.errs, FieldError{Index: index, Field: field, Message: message})
+}
+
+func (v *validator) name(index int, field, name string) {
+	if strings.TrimSpace(name) == "" {
+		v.add(index, field, "must not be empty")
+	}
+}
+
+func (v *validator) positive(index int, field string, amount Money) {
+	if amount.Sign() <= 0 {
+		v.add(index, field, "must be positive")
+	}
+}
+
+// contributors checks the contributors of a transaction replace its payer and pay its whole amount
+func (v *validator) contributors(index int, t Transaction) {
+	if t.From != "" {
+		v.add(index, "from", "must be empty with contributors")
+	}
+	names := map[string]bool{}
+	sum := t.Amount.Zero()
+	for i, c := range t.Contributors {
+		field := fmt.Sprintf("contributors[%d]", i)
+		v.name(index, field+".name", c.Name)
+		v.positive(index, field+".amount", c.Amount)
+		if names[c.Name] {
+			v.add(index, field+".name", "must be unique")
+		}
+		names[c.Name] = true
+		var err error
+		if sum, err = sum.CheckedAdd(c.Amount); err != nil {
+			v.add(index, "contributors", "must sum to the amount, their sum is out of range")
+			return
+		}
+	}
+	if !sum.Equal(t.Amount) {
+		v.add(index, "contributors", fmt.Sprintf("must sum to the amount %s, got %s", t.Amount, sum))
+	}
+}
+
+// currency checks the currency, when given, is a 3 letter code
+func (v *validator) currency(index int, code string) {
+	if code != "" && !ValidCurrency(code) {
+		v.add(index, "currency", "must be a 3 letter code")
+	}
+}
+
+func (v *validator) groupSize(people map[string]bool, maxSize int) {
+	if maxSize > 0 && len(people) > maxSize {
+		v.add(-1, "names", fmt.Sprintf("must not have more than %d distinct people, got %d", maxSize, len(people)))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// ValidateTransactions checks every transaction has both names, or contributors summing to its amount, and a positive amount,
+// and there are at most maxGroupSize people involved (0 means no limit)
+// amounts are exact decimals, so NaN or infinite values are already rejected when parsing them
+func ValidateTransactions(transactions Transactions, maxGroupSize int) error {
+	var v validator
+	people := map[string]bool{}
+	for i, t := range transactions {
+		if len(t.Contributors) == 0 {
+			v.name(i, "from", t.From)
+		} else {
+			v.contributors(i, t)
+		}
+		v.name(i, "to", t.To)
+		v.positive(i, "amount", t.Amount)
+		v.currency(i, t.Currency)
+		for _, p := range t.Payments() {
+			people[p.From] = true
+		}
+		people[t.To] = true
+	}
+	v.groupSize(people, maxGroupSize)
+	return v.err()
+}
+
+// ValidateBalances checks every balance has a unique name, and there are at most maxGroupSize people
+// (0 means no limit)
+func ValidateBalances(balances Balances, maxGroupSize int) error {
+	var v validator
+	people := map[string]bool{}
+	for i, b := range balances {
+		v.name(i, "name", b.Name)
+		v.currency(i, b.Currency)
+		if people[b.Name] {
+			v.add(i, "name", "must be unique")
+		}
+		people[b.Name] = true
+	}
+	v.groupSize(people, maxGroupSize)
+	return v.err()
+}
+
+// validateExpensesGroupSize checks there are at most maxGroupSize people paying or participating in the expenses
+// (0 means no limit), before they are expanded into transactions
+func validateExpensesGroupSize(expenses Expenses, maxGroupSize int) error {
+	var v validator
+	people := map[string]bool{}
+	for _, e := range expenses {
+		for _, c := range e.PaidBy {
+			people[c.Name] = true
+		}
+		for _, p := range e.Participants {
+			people[p.Name] = true
+		}
+	}
+	v.groupSize(people, maxGroupSize)
+	return v.err()
+}
+
+// validateReceiptGroupSize checks there are at most maxGroupSize people paying or consuming the receipt items
+// (0 means no limit), before it is split between them
+func validateReceiptGroupSize(receipt Receipt, maxGroupSize int) error {
+	var v validator
+	people := map[string]bool{}
+	for _, c := range receipt.PaidBy {
+		people[c.Name] = true
+	}
+	for _, item := range receipt.Items {
+		for _, name := range item.Consumers {
+			people[name] = true
+		}
+	}
+	v.groupSize(people, maxGroupSize)
+	return v.err()
+}
+
+// validateZeroSum checks the balances sum to zero, within the tolerance
+func validateZeroSum(balances Balances, tolerance Money) error {
+	sum := Money{}
+	for _, b := range balances {
+		var err error
+		if sum, err = sum.CheckedAdd(b.Amount); err != nil {
+			var v validator
+			v.add(-1, "amount", "balances must sum to zero, their sum is out of range")
+			return v.err()
+		}
+	}
+	if sum.Abs().Cmp(tolerance) > 0 {
+		var v validator
+		v.add(-1, "amount", fmt.Sprintf("balances must sum to zero, got %s", sum))
+		return v.err()
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Validate_Transactions(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		input        Transactions
+		maxGroupSize int
+		expected     []FieldError
+	}{
+		{
+			name: "when valid",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10")},
+			},
+		},
+		{
+			name: "when empty names and invalid amounts",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10")},
+				{From: " ", To: "B", Amount: money("-1")},
+				{From: "A", To: "", Amount: money("0")},
+			},
+			expected: []FieldError{
+				{Index: 1, Field: "from", Message: "must not be empty"},
+				{Index: 1, Field: "amount", Message: "must be positive"},
+				{Index: 2, Field: "to", Message: "must not be empty"},
+				{Index: 2, Field: "amount", Message: "must be positive"},
+			},
+		},
+		{
+			name: "when invalid currency",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10"), Currency: "eur"},
+				{From: "A", To: "B", Amount: money("10"), Currency: "XXXX"},
+				{From: "A", To: "B", Amount: money("10"), Currency: "E1R"},
+			},
+			expected: []FieldError{
+				{Index: 1, Field: "currency", Message: "must be a 3 letter code"},
+				{Index: 2, Field: "currency", Message: "must be a 3 letter code"},
+			},
+		},
+		{
+			name: "when valid contributors",
+			input: Transactions{
+				{To: "B", Amount: money("10"), Contributors: []Contribution{{Name: "A", Amount: money("4")}, {Name: "B", Amount: money("6")}}},
+			},
+		},
+		{
+			name: "when invalid contributors",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10"), Contributors: []Contribution{{Name: "A", Amount: money("10")}}},
+				{To: "B", Amount: money("10"), Contributors: []Contribution{{Name: "A", Amount: money("4")}, {Name: "", Amount: money("0")}}},
+				{To: "B", Amount: money("10"), Contributors: []Contribution{{Name: "A", Amount: money("5")}, {Name: "A", Amount: money("5")}}},
+			},
+			expected: []FieldError{
+				{Index: 0, Field: "from", Message: "must be empty with contributors"},
+				{Index: 1, Field: "contributors[1].name", Message: "must not be empty"},
+				{Index: 1, Field: "contributors[1].amount", Message: "must be positive"},
+				{Index: 1, Field: "contributors", Message: "must sum to the amount 10.00, got 4.00"},
+				{Index: 2, Field: "contributors[1].name", Message: "must be unique"},
+			},
+		},
+		{
+			name: "when group is too big",
+			input: Transactions{
+				{From: "A", To: "B", Amount: money("10")},
+				{From: "C", To: "B", Amount: money("10")},
+			},
+			maxGroupSize: 2,
+			expected: []FieldError{
+				{Index: -1, Field: "names", Message: "must not have more than 2 distinct people, got 3"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := ValidateTransactions(s.input, s.maxGroupSize)
+			assertFieldErrors(t, s.expected, err)
+		})
+	}
+}
+
+func Test_Validate_Balances(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		input    Balances
+		expected []FieldError
+	}{
+		{
+			name: "when valid",
+			input: Balances{
+				{Name: "A", Amount: money("10")},
+				{Name: "B", Amount: money("-10")},
+			},
+		},
+		{
+			name: "when empty and duplicated names",
+			input: Balances{
+				{Name: "A", Amount: money("10")},
+				{Name: "", Amount: money("-5")},
+				{Name: "A", Amount: money("-5")},
+			},
+			expected: []FieldError{
+				{Index: 1, Field: "name", Message: "must not be empty"},
+				{Index: 2, Field: "name", Message: "must be unique"},
+			},
+		},
+		{
+			name: "when invalid currency",
+			input: Balances{
+				{Name: "A", Amount: money("10"), Currency: "EU"},
+				{Name: "B", Amount: money("-10"), Currency: "EUR"},
+			},
+			expected: []FieldError{
+				{Index: 0, Field: "currency", Message: "must be a 3 letter code"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := ValidateBalances(s.input, 0)
+			assertFieldErrors(t, s.expected, err)
+		})
+	}
+}
+
+func Test_Service_Minimize_Zero_Sum(t *testing.T) {
+	service := NewService()
+
+	_, err := service.Minimize(context.Background(), Balances{
+		{Name: "A", Amount: money("30")},
+		{Name: "B", Amount: money("-5")},
+	})
+	assertFieldErrors(t, []FieldError{{Index: -1, Field: "amount", Message: "balances must sum to zero, got 25.00"}}, err)
+
+	// within tolerance
+	if _, err := service.Minimize(context.Background(), Balances{
+		{Name: "A", Amount: money("10.01")},
+		{Name: "B", Amount: money("-10")},
+	}); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+}
+
+func assertFieldErrors(t *testing.T, expected []FieldError, err error) {
+	t.Helper()
+	if expected == nil {
+		if err != nil {
+			t.Errorf("unexpected error: %+v", err)
+		}
+		return
+	}
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("\nExpected:	%+v\nGot:		%+v", ErrValidation, err)
+	}
+	if !reflect.DeepEqual(expected, ve.Errors) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, ve.Errors)
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/csvx"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// calcFlags are the flags shared by the offline calculation commands
+type calcFlags struct {
+	format    string
+	output    string
+	currency  string
+	ratesFile string
+}
+
+func (cf *calcFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&cf.format, "format", "auto", "input format: auto, json or csv")
+	fs.StringVar(&cf.output, "output", "table", "output format: table, json or csv")
+	fs.StringVar(&cf.currency, "currency", "", "settlement currency every amount is converted into")
+	fs.StringVar(&cf.ratesFile, "rates-file", "", "exchange rates file, required to convert between currencies")
+}
+
+func (cf *calcFlags) validate() error {
+	if cf.output != "table" && cf.output != formatJSON && cf.output != formatCSV {
+		return fmt.Errorf("-output must be table, json or csv, got %q", cf.output)
+	}
+	if cf.format != "auto" && cf.format != formatJSON && cf.format != formatCSV {
+		return fmt.Errorf("-format must be auto, json or csv, got %q", cf.format)
+	}
+	return nil
+}
+
+func (cf *calcFlags) service() *accounting.Service {
+	if cf.ratesFile == "" {
+		return accounting.NewService()
+	}
+	return accounting.NewService(accounting.WithRateProvider(accounting.NewFileRates(cf.ratesFile)))
+}
+
+func (cf *calcFlags) options() []accounting.Option {
+	if cf.currency == "" {
+		return nil
+	}
+	return []accounting.Option{accounting.WithSettlementCurrency(cf.currency)}
+}
+
+// stringList is a flag that can be repeated
+type stringList []string
+
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ",")
+}
+
+func (sl *stringList) Set(v string) error {
+	*sl = append(*sl, v)
+	return nil
+}
+
+func newFlagSet(env Env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.Stderr, "Usage: bill-splitter %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// balance calculates balances from the transactions in a file or stdin
+func balance(env Env, args []string) int {
+	var (
+		cf   calcFlags
+		asOf string
+	)
+	fs := newFlagSet(env, "balance", "[transactions file, - or nothing for stdin]")
+	cf.register(fs)
+	fs.StringVar(&asOf, "as-of", "", "only transactions that occurred up to this RFC 3339 time or `date`")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := cf.validate(); err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return ExitUsage
+	}
+	opts := cf.options()
+	if asOf != "" {
+		t, err := accounting.ParseTime(asOf)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "-as-of: %v\n", err)
+			return ExitUsage
+		}
+		opts = append(opts, accounting.WithAsOf(t))
+	}
+
+	var transactions accounting.Transactions
+	if err := readInput(env, fs.Arg(0), cf.format, &transactions, csvx.ReadTransactions); err != nil {
+		return fail(env, err)
+	}
+
+	balances, err := cf.service().Calculate(context.Background(), transactions, opts...)
+	if err != nil {
+		return fail(env, err)
+	}
+	if err := writeOutput(env.Stdout, cf.output, balances, balancesTable, csvx.WriteBalances); err != nil {
+		return fail(env, err)
+	}
+	return ExitOK
+}
+
+// settle minimizes the transactions settling the balances in a file or stdin
+func settle(env Env, args []string) int {
+	var (
+		cf                                calcFlags
+		algorithm, objective              string
+		forbidden, preferred, maxTransfer stringList
+	)
+	fs := newFlagSet(env, "settle", "[balances file, - or nothing for stdin]")
+	cf.register(fs)
+	fs.StringVar(&algorithm, "algorithm", "", "how transactions are minimized: auto, greedy or exact")
+	fs.StringVar(&objective, "objective", "", "what the settlement optimizes for: count, volume or payers")
+	fs.Var(&forbidden, "forbidden", "`payer:payee` pair that can not pay directly, can repeat")
+	fs.Var(&preferred, "preferred", "`name` collecting the payments of the group, can repeat")
+	fs.Var(&maxTransfer, "max-transfer", "`payer:amount` maximum amount per transfer, can repeat")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if err := cf.validate(); err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return ExitUsage
+	}
+
+	opts := cf.options()
+	if algorithm != "" {
+		opts = append(opts, accounting.WithAlgorithm(accounting.Algorithm(algorithm)))
+	}
+	if objective != "" {
+		opts = append(opts, accounting.WithObjective(accounting.Objective(objective)))
+	}
+	constraints, err := accounting.ParseConstraints(forbidden, preferred, maxTransfer)
+	if err != nil {
+		return fail(env, err)
+	}
+	if !constraints.IsZero() {
+		opts = append(opts, accounting.WithConstraints(constraints))
+	}
+
+	var balances accounting.Balances
+	if err := readInput(env, fs.Arg(0), cf.format, &balances, csvx.ReadBalances); err != nil {
+		return fail(env, err)
+	}
+
+	statement, err := cf.service().Minimize(context.Background(), balances, opts...)
+	if err != nil {
+		return fail(env, err)
+	}
+	if err := writeOutput(env.Stdout, cf.output, statement, statementTable, csvx.WriteStatement); err != nil {
+		return fail(env, err)
+	}
+	return ExitOK
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/csvx"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// exit codes of the commands
+const (
+	ExitOK = 0
+	// ExitError the command failed, e.g. a file could not be read
+	ExitError = 1
+	// ExitUsage the command line is wrong
+	ExitUsage = 2
+	// ExitInvalid the input is invalid, e.g. negative amounts or balances not summing to zero
+	ExitInvalid = 3
+)
+
+// Env is what commands need from the process, replaced by tests
+type Env struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Getenv func(string) string
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(env Env, args []string) int
+}
+
+var commands = []command{
+	{"serve", "start the HTTP server", serve},
+	{"balance", "calculate balances from transactions", balance},
+	{"settle", "minimize the transactions settling balances", settle},
+}
+
+// Run runs the command named by the first argument and returns the process exit code
+// without a command, or when the first argument is a flag, the server is started as before commands existed
+func Run(env Env, args []string) int {
+	help := len(args) > 0 && slices.Contains([]string{"help", "-h", "-help", "--help"}, args[0])
+	if help {
+		usage(env.Stdout)
+		return ExitOK
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(env, args)
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(env, args[1:])
+		}
+	}
+	fmt.Fprintf(env.Stderr, "unknown command %q\n\n", args[0])
+	usage(env.Stderr)
+	return ExitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: bill-splitter <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(w, "\nRun `bill-splitter <command> -h` for the flags of a command.")
+}
+
+// parseFlags parses the command flags, returning the exit code to stop with when parsing fails or help is asked
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK, false
+		}
+		return ExitUsage, false
+	}
+	return ExitOK, true
+}
+
+// fail reports the error and picks the exit code for it
+func fail(env Env, err error) int {
+	var validationErr *accounting.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(env.Stderr, "invalid input:")
+		for _, fe := range validationErr.Errors {
+			fmt.Fprintf(env.Stderr, "  %s\n", fe)
+		}
+		return ExitInvalid
+	}
+
+	fmt.Fprintf(env.Stderr, "error: %v\n", err)
+	for _, invalid := range []error{
+		errInvalidInput,
+		csvx.ErrInvalidCSV,
+		accounting.ErrInvalidAmount,
+		accounting.ErrAmountTooLarge,
+		accounting.ErrMixedCurrencies,
+		accounting.ErrRateNotFound,
+		accounting.ErrInvalidOption,
+		accounting.ErrInfeasibleSettlement,
+	} {
+		if errors.Is(err, invalid) {
+			return ExitInvalid
+		}
+	}
+	return ExitError
+}
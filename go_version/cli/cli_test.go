@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Run(t *testing.T) {
+	dir := t.TempDir()
+	transactionsFile := filepath.Join(dir, "transactions.json")
+	_ = os.WriteFile(transactionsFile, []byte(`[{"from":"A","to":"B","amount":40},{"from":"A","to":"C","amount":10}]`), 0o644)
+	balancesFile := filepath.Join(dir, "balances.csv")
+	_ = os.WriteFile(balancesFile, []byte("name,amount,currency\nA,770,THB\nB,-20,EUR\n"), 0o644)
+	ratesFile := filepath.Join(dir, "rates.json")
+	_ = os.WriteFile(ratesFile, []byte(`{"base":"EUR","rates":{"THB":"38.5"}}`), 0o644)
+
+	scenarios := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:         "should calculate balances from csv stdin",
+			args:         []string{"balance"},
+			stdin:        "from,to,amount\nA,B,40\nB,C,40\nC,A,10\n",
+			expectedCode: ExitOK,
+			expectedStdout: `NAME  AMOUNT  CURRENCY
+A     30.00   
+B     0.00    
+C     -30.00  
+`,
+		},
+		{
+			name:           "should calculate balances as of a date",
+			args:           []string{"balance", "-as-of", "2025-01-02", "-output", "csv"},
+			stdin:          "from,to,amount,date\nA,B,40,2025-01-01\nB,A,300,2025-01-03\n",
+			expectedCode:   ExitOK,
+			expectedStdout: "name,amount,currency\nA,40.00,\nB,-40.00,\n",
+		},
+		{
+			name:           "should fail on invalid as of",
+			args:           []string{"balance", "-as-of", "yesterday"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "-as-of: invalid time: \"yesterday\" is not a date like 2006-01-02 or a time like 2006-01-02T15:04:05Z\n",
+		},
+		{
+			name:           "should calculate balances from json file as json",
+			args:           []string{"balance", "-output", "json", transactionsFile},
+			expectedCode:   ExitOK,
+			expectedStdout: "[\n  {\n    \"name\": \"A\",\n    \"amount\": 50.00\n  },\n  {\n    \"name\": \"B\",\n    \"amount\": -40.00\n  },\n  {\n    \"name\": \"C\",\n    \"amount\": -10.00\n  }\n]\n",
+		},
+		{
+			name:         "should settle balances from json stdin",
+			args:         []string{"settle", "-objective", "volume"},
+			stdin:        `[{"name":"A","amount":30},{"name":"B","amount":0},{"name":"C","amount":-30}]`,
+			expectedCode: ExitOK,
+			expectedStdout: `FROM  TO  AMOUNT  CURRENCY
+C     A   30.00   
+`,
+		},
+		{
+			name:         "should settle balances with constraints",
+			args:         []string{"settle", "-forbidden", "C:A", "-max-transfer", "C:20"},
+			stdin:        "A,30\nB,0\nC,-30\n",
+			expectedCode: ExitOK,
+			expectedStdout: `FROM  TO  AMOUNT  CURRENCY
+B     A   30.00   
+C     B   20.00   
+C     B   10.00   
+`,
+		},
+		{
+			name:         "should settle converted balances",
+			args:         []string{"settle", "-currency", "EUR", "-rates-file", ratesFile, balancesFile},
+			expectedCode: ExitOK,
+			expectedStdout: `FROM  TO  AMOUNT  CURRENCY
+B     A   20.00   EUR
+
+rate 1 THB = 0.02597403 EUR
+`,
+		},
+		{
+			name:           "should settle balances as csv",
+			args:           []string{"settle", "-output", "csv"},
+			stdin:          "name,amount\nA,30\nC,-30\n",
+			expectedCode:   ExitOK,
+			expectedStdout: "from,to,amount,currency,date,description\nC,A,30.00,,,\n",
+		},
+		{
+			name:           "should report nothing to settle",
+			args:           []string{"settle", "-"},
+			stdin:          "A,0\n",
+			expectedCode:   ExitOK,
+			expectedStdout: "nothing to settle\n",
+		},
+		{
+			name:           "should fail on validation errors",
+			args:           []string{"balance"},
+			stdin:          "A,B,-1\n,C,3\n",
+			expectedCode:   ExitInvalid,
+			expectedStderr: "invalid input:\n  [0].amount must be positive\n  [1].from must not be empty\n",
+		},
+		{
+			name:           "should fail on balances not summing to zero",
+			args:           []string{"settle"},
+			stdin:          "A,10\nB,-5\n",
+			expectedCode:   ExitInvalid,
+			expectedStderr: "invalid input:\n  amount balances must sum to zero, got 5.00\n",
+		},
+		{
+			name:           "should fail on malformed csv",
+			args:           []string{"balance"},
+			stdin:          "A,B,40\nA,B\n",
+			expectedCode:   ExitInvalid,
+			expectedStderr: "error: line 2: expected at least 3 columns, got 2\n",
+		},
+		{
+			name:           "should fail on unreadable file",
+			args:           []string{"balance", filepath.Join(dir, "missing.json")},
+			expectedCode:   ExitError,
+			expectedStderr: "error: open " + filepath.Join(dir, "missing.json") + ": no such file or directory\n",
+		},
+		{
+			name:           "should fail on invalid output",
+			args:           []string{"balance", "-output", "xml"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "-output must be table, json or csv, got \"xml\"\n",
+		},
+		{
+			name:           "should fail on unknown command",
+			args:           []string{"split"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "unknown command \"split\"",
+		},
+		{
+			name:           "should print usage",
+			args:           []string{"help"},
+			expectedCode:   ExitOK,
+			expectedStdout: "Usage: bill-splitter <command> [flags]",
+		},
+		{
+			name:           "should fail serving with invalid config",
+			args:           []string{"serve", "-addr", "nowhere"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "failed to load config: invalid config: server.addr \"nowhere\" is not a host:port address\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			env := Env{
+				Stdin:  strings.NewReader(s.stdin),
+				Stdout: &stdout,
+				Stderr: &stderr,
+				Getenv: func(string) string { return "" },
+			}
+
+			actualCode := Run(env, s.args)
+
+			if s.expectedCode != actualCode {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%s)", s.expectedCode, actualCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), s.expectedStdout) || (s.expectedStdout == "" && stdout.Len() > 0) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedStdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), s.expectedStderr) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
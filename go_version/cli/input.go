@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var errInvalidInput = errors.New("invalid input")
+
+// readInput decodes the file at path, or stdin when path is empty or `-`, into v
+// JSON or CSV is detected by the file extension or, for stdin, by the first character
+func readInput[T any](env Env, path, format string, v *T, parseCSV func(io.Reader) (T, error)) error {
+	var r io.Reader = env.Stdin
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	br := bufio.NewReader(r)
+	if format == "auto" {
+		format = detectFormat(path, br)
+	}
+
+	if format == formatCSV {
+		parsed, err := parseCSV(br)
+		if err != nil {
+			return err
+		}
+		*v = parsed
+		return nil
+	}
+	if err := json.NewDecoder(br).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidInput, err)
+	}
+	return nil
+}
+
+func detectFormat(path string, br *bufio.Reader) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".csv":
+		return formatCSV
+	}
+	peek, _ := br.Peek(512)
+	if trimmed := bytes.TrimLeft(peek, " \t\r\n\ufeff"); len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return formatJSON
+	}
+	return formatCSV
+}
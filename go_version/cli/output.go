@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bill-splitter/accounting"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// writeOutput writes v as indented JSON, CSV or as the table written by table
+func writeOutput[T any](w io.Writer, output string, v T, table func(io.Writer, T), csv func(io.Writer, T) error) error {
+	switch output {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatCSV:
+		return csv(w, v)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	table(tw, v)
+	return tw.Flush()
+}
+
+func balancesTable(w io.Writer, balances accounting.Balances) {
+	fmt.Fprintln(w, "NAME\tAMOUNT\tCURRENCY")
+	for _, b := range balances {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", b.Name, b.Amount, b.Currency)
+	}
+}
+
+func statementTable(w io.Writer, statement accounting.Statement) {
+	if len(statement.Transactions) == 0 {
+		fmt.Fprintln(w, "nothing to settle")
+		return
+	}
+	fmt.Fprintln(w, "FROM\tTO\tAMOUNT\tCURRENCY")
+	for _, t := range statement.Transactions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.From, t.To, t.Amount, t.Currency)
+	}
+	for _, r := range statement.Rates {
+		fmt.Fprintf(w, "\nrate 1 %s = %s %s\n", r.From, r.Rate, r.To)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/config"
+	"bill-splitter/eventlog"
+	"bill-splitter/httpx"
+	"bill-splitter/logx"
+	"bill-splitter/metrics"
+	"bill-splitter/storage"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+)
+
+// serve starts the HTTP server configured by file, environment variables and flags
+func serve(env Env, args []string) int {
+	cfg, err := config.Load("serve", args, env.Getenv, env.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "failed to load config: %+v\n", err)
+		return ExitUsage
+	}
+	level, _ := cfg.Log.SlogLevel()
+	logger, err := logx.New(env.Stderr, cfg.Log.Format, level)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "failed to set up logs: %+v\n", err)
+		return ExitUsage
+	}
+	slog.SetDefault(logger)
+
+	registry := metrics.NewRegistry()
+	metrics.RegisterRuntime(registry)
+	opts, err := serviceOptions(cfg)
+	if err != nil {
+		slog.Error("failed to set up service", "error", err)
+		return ExitError
+	}
+	opts = append(opts, accounting.WithRecorder(metrics.NewServiceMetrics(registry)))
+	accService := accounting.NewService(opts...)
+	s := httpx.NewServer(
+		cfg.Server,
+		accService, accService, accService, accService,
+		httpx.WithMetrics(registry),
+		httpx.WithReadinessCheck("storage", accService.CheckStorage),
+	)
+	if err := s.Run(); err != nil {
+		slog.Error("server failed", "error", err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+// serviceOptions sets up the storage backend and exchange rates from the config
+func serviceOptions(cfg config.Config) ([]accounting.ServiceOption, error) {
+	var opts []accounting.ServiceOption
+	switch cfg.Storage.Backend {
+	case config.StorageFile:
+		ledger, err := storage.NewFileLedger(cfg.Storage.Dir)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, accounting.WithLedger(ledger))
+	case config.StorageEventLog:
+		log, err := eventlog.Open(cfg.Storage.Dir)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, accounting.WithLedger(eventlog.NewLedger(log)))
+	}
+	if cfg.RatesFile != "" {
+		opts = append(opts, accounting.WithRateProvider(accounting.NewFileRates(cfg.RatesFile)))
+	}
+	return opts, nil
+}
@@ -0,0 +1,301 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of every environment variable read by Load
+const EnvPrefix = "BILL_SPLITTER_"
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+const (
+	StorageMemory   = "memory"
+	StorageFile     = "file"
+	StorageEventLog = "eventlog"
+)
+
+// Config is the whole application configuration
+type Config struct {
+	Server  Server  `json:"server"`
+	Log     Log     `json:"log"`
+	Storage Storage `json:"storage"`
+	// RatesFile is the exchange rates file, rates are disabled when empty
+	RatesFile string `json:"rates_file"`
+}
+
+// Server configures the http server
+type Server struct {
+	Addr            string   `json:"addr"`
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// MaxBodyBytes is the biggest request body accepted
+	MaxBodyBytes int64 `json:"max_body_bytes"`
+}
+
+// Log configures the application logs
+type Log struct {
+	Level string `json:"level"`
+	// Format is either json or text
+	Format string `json:"format"`
+}
+
+// Storage configures where groups and their transactions are kept
+type Storage struct {
+	Backend string `json:"backend"`
+	// Dir is the data directory of the file and eventlog backends
+	Dir string `json:"dir"`
+}
+
+// Default returns the configuration used when nothing else is informed
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:            ":8000",
+			ReadTimeout:     Duration(10 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(60 * time.Second),
+			ShutdownTimeout: Duration(15 * time.Second),
+			MaxBodyBytes:    1 << 20,
+		},
+		Log:       Log{Level: "info", Format: "json"},
+		Storage:   Storage{Backend: StorageMemory, Dir: "data"},
+		RatesFile: "rates.json",
+	}
+}
+
+// Duration is a time.Duration read from strings like "5s" or "1m30s"
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %s", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// setting is a single value that can be set by environment variable and flag
+type setting struct {
+	name  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+var settings = []setting{
+	{"addr", "listen address", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"read-timeout", "maximum duration to read a request", durationSetter(func(c *Config) *Duration { return &c.Server.ReadTimeout })},
+	{"write-timeout", "maximum duration to write a response", durationSetter(func(c *Config) *Duration { return &c.Server.WriteTimeout })},
+	{"idle-timeout", "maximum duration to keep idle connections", durationSetter(func(c *Config) *Duration { return &c.Server.IdleTimeout })},
+	{"shutdown-timeout", "maximum duration to drain requests on shutdown", durationSetter(func(c *Config) *Duration { return &c.Server.ShutdownTimeout })},
+	{"max-body-bytes", "biggest request body accepted, in bytes", func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		c.Server.MaxBodyBytes = n
+		return nil
+	}},
+	{"log-level", "log level: debug, info, warn or error", func(c *Config, v string) error {
+		c.Log.Level = v
+		return nil
+	}},
+	{"log-format", "log format: json or text", func(c *Config, v string) error {
+		c.Log.Format = v
+		return nil
+	}},
+	{"storage", "storage backend: memory, file or eventlog", func(c *Config, v string) error {
+		c.Storage.Backend = v
+		return nil
+	}},
+	{"storage-dir", "data directory of the file and eventlog storages", func(c *Config, v string) error {
+		c.Storage.Dir = v
+		return nil
+	}},
+	{"rates-file", "exchange rates file, empty to disable conversions", func(c *Config, v string) error {
+		c.RatesFile = v
+		return nil
+	}},
+}
+
+func durationSetter(field func(c *Config) *Duration) func(c *Config, value string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = Duration(d)
+		return nil
+	}
+}
+
+// Load builds the configuration from, in increasing precedence: defaults, config file, environment variables and
+// command line flags. The config file is informed with the -config flag or the BILL_SPLITTER_CONFIG variable
+func Load(name string, args []string, getenv func(string) string, output io.Writer) (Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	path := fs.String("config", getenv(EnvPrefix+"CONFIG"), "config file, YAML or JSON")
+	for _, s := range settings {
+		fs.String(s.name, "", fmt.Sprintf("%s (env %s)", s.usage, s.env()))
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	c := Default()
+	if *path != "" {
+		if err := c.readFile(*path); err != nil {
+			return Config{}, err
+		}
+	}
+	for _, s := range settings {
+		if v, ok := lookup(getenv, s.env()); ok {
+			if err := s.set(&c, v); err != nil {
+				return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, s.env(), err)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.name == f.Name && err == nil {
+				if setErr := s.set(&c, f.Value.String()); setErr != nil {
+					err = fmt.Errorf("%w: -%s: %v", ErrInvalidConfig, s.name, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+	return c, c.Validate()
+}
+
+// lookup reads a variable, empty values are taken as not set
+func lookup(getenv func(string) string, key string) (string, bool) {
+	v := getenv(key)
+	return v, v != ""
+}
+
+// readFile merges a YAML or JSON file into the config, keys not present keep their current values
+// YAML is converted to JSON first, so both formats are decoded and checked for unknown keys the same way
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yml" || ext == ".yaml" {
+		if data, err = yamlToJSON(data); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return nil
+}
+
+// yamlToJSON re-encodes a YAML document as JSON, an empty document is an empty object
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(document)
+}
+
+// Validate checks every value, reporting all problems at once
+func (c Config) Validate() error {
+	var problems []string
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		problems = append(problems, fmt.Sprintf("server.addr %q is not a host:port address", c.Server.Addr))
+	}
+	for name, d := range map[string]Duration{
+		"server.read_timeout":  c.Server.ReadTimeout,
+		"server.write_timeout": c.Server.WriteTimeout,
+		"server.idle_timeout":  c.Server.IdleTimeout,
+	} {
+		if d < 0 {
+			problems = append(problems, fmt.Sprintf("%s must not be negative", name))
+		}
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server.shutdown_timeout must be positive")
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		problems = append(problems, "server.max_body_bytes must be positive")
+	}
+	if _, err := c.Log.SlogLevel(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		problems = append(problems, fmt.Sprintf("log.format %q must be json or text", c.Log.Format))
+	}
+	switch c.Storage.Backend {
+	case StorageMemory:
+	case StorageFile, StorageEventLog:
+		if c.Storage.Dir == "" {
+			problems = append(problems, fmt.Sprintf("storage.dir is required by the %s backend", c.Storage.Backend))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("storage.backend %q must be memory, file or eventlog", c.Storage.Backend))
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// SlogLevel converts the configured level name
+func (l Log) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return level, fmt.Errorf("log.level %q must be debug, info, warn or error", l.Level)
+	}
+	return level, nil
+}
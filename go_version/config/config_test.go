@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Load(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.json")
+	_ = os.WriteFile(file, []byte(`{
+  "server": {"addr": ":9000", "read_timeout": "5s"},
+  "log": {"level": "debug"},
+  "storage": {"backend": "file", "dir": "/var/lib/bill-splitter"}
+}`), 0o644)
+	partialFile := filepath.Join(dir, "partial.json")
+	_ = os.WriteFile(partialFile, []byte(`{"server":{"max_body_bytes":2048},"rates_file":""}`), 0o644)
+	yamlFile := filepath.Join(dir, "config.yml")
+	_ = os.WriteFile(yamlFile, []byte(`
+server:
+  addr: ":9000"   # overridden by env and flags
+  read_timeout: 5s
+  max_body_bytes: 2048
+log:
+  level: debug
+storage:
+  backend: file
+  dir: "/var/lib/bill-splitter"
+`), 0o644)
+	emptyYAMLFile := filepath.Join(dir, "empty.yaml")
+	_ = os.WriteFile(emptyYAMLFile, []byte("# nothing set\n"), 0o644)
+	unknownYAMLFile := filepath.Join(dir, "unknown.yml")
+	_ = os.WriteFile(unknownYAMLFile, []byte("server:\n  port: 8000\n"), 0o644)
+	invalidYAMLFile := filepath.Join(dir, "invalid.yml")
+	_ = os.WriteFile(invalidYAMLFile, []byte("server: [\n"), 0o644)
+	unknownFile := filepath.Join(dir, "unknown.json")
+	_ = os.WriteFile(unknownFile, []byte(`{"server":{"port":8000}}`), 0o644)
+
+	withDefaults := func(modify func(c *Config)) Config {
+		c := Default()
+		modify(&c)
+		return c
+	}
+
+	scenarios := []struct {
+		name           string
+		args           []string
+		env            map[string]string
+		expectedConfig Config
+		expectedError  string
+	}{
+		{
+			name:           "should use defaults",
+			expectedConfig: Default(),
+		},
+		{
+			name: "should read json file",
+			args: []string{"-config", file},
+			expectedConfig: withDefaults(func(c *Config) {
+				c.Server.Addr = ":9000"
+				c.Server.ReadTimeout = Duration(5 * time.Second)
+				c.Log.Level = "debug"
+				c.Storage = Storage{Backend: StorageFile, Dir: "/var/lib/bill-splitter"}
+			}),
+		},
+		{
+			name: "should read json file from environment",
+			env:  map[string]string{"BILL_SPLITTER_CONFIG": partialFile},
+			expectedConfig: withDefaults(func(c *Config) {
+				c.Server.MaxBodyBytes = 2048
+				c.RatesFile = ""
+			}),
+		},
+		{
+			name: "should prefer environment over file and flags over environment",
+			args: []string{"-config", file, "-addr", ":7000"},
+			env:  map[string]string{"BILL_SPLITTER_ADDR": ":8080", "BILL_SPLITTER_LOG_LEVEL": "warn"},
+			expectedConfig: withDefaults(func(c *Config) {
+				c.Server.Addr = ":7000"
+				c.Server.ReadTimeout = Duration(5 * time.Second)
+				c.Log.Level = "warn"
+				c.Storage = Storage{Backend: StorageFile, Dir: "/var/lib/bill-splitter"}
+			}),
+		},
+		{
+			name: "should read yaml file",
+			args: []string{"-config", yamlFile},
+			expectedConfig: withDefaults(func(c *Config) {
+				c.Server.Addr = ":9000"
+				c.Server.ReadTimeout = Duration(5 * time.Second)
+				c.Server.MaxBodyBytes = 2048
+				c.Log.Level = "debug"
+				c.Storage = Storage{Backend: StorageFile, Dir: "/var/lib/bill-splitter"}
+			}),
+		},
+		{
+			name:           "should read empty yaml file",
+			args:           []string{"-config", emptyYAMLFile},
+			expectedConfig: Default(),
+		},
+		{
+			name:          "should reject unknown keys in yaml file",
+			args:          []string{"-config", unknownYAMLFile},
+			expectedError: `invalid config: ` + unknownYAMLFile + `: json: unknown field "port"`,
+		},
+		{
+			name:          "should reject invalid yaml file",
+			args:          []string{"-config", invalidYAMLFile},
+			expectedError: `invalid config: ` + invalidYAMLFile + `: yaml: line 1: did not find expected node content`,
+		},
+		{
+			name:          "should reject invalid duration",
+			env:           map[string]string{"BILL_SPLITTER_READ_TIMEOUT": "5"},
+			expectedError: `invalid config: BILL_SPLITTER_READ_TIMEOUT: time: missing unit in duration "5"`,
+		},
+		{
+			name:          "should reject unknown keys in file",
+			args:          []string{"-config", unknownFile},
+			expectedError: `invalid config: ` + unknownFile + `: json: unknown field "port"`,
+		},
+		{
+			name:          "should report every invalid value",
+			args:          []string{"-addr", "8000", "-storage", "s3", "-log-level", "loud", "-log-format", "xml", "-max-body-bytes", "0"},
+			expectedError: `invalid config: log.format "xml" must be json or text, log.level "loud" must be debug, info, warn or error, server.addr "8000" is not a host:port address, server.max_body_bytes must be positive, storage.backend "s3" must be memory, file or eventlog`,
+		},
+		{
+			name:          "should require directory for file storage",
+			args:          []string{"-storage", "file", "-storage-dir", ""},
+			expectedError: `invalid config: storage.dir is required by the file backend`,
+		},
+		{
+			name:          "should require directory for eventlog storage",
+			args:          []string{"-storage", "eventlog", "-storage-dir", ""},
+			expectedError: `invalid config: storage.dir is required by the eventlog backend`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return s.env[key]
+			}
+
+			actualConfig, err := Load("test", s.args, getenv, io.Discard)
+
+			if s.expectedError != "" {
+				if err == nil || err.Error() != s.expectedError || !errors.Is(err, ErrInvalidConfig) {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(s.expectedConfig, actualConfig) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedConfig, actualConfig)
+			}
+		})
+	}
+}
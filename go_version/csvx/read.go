@@ -0,0 +1,204 @@
+package csvx
+
+import (
+	"bill-splitter/accounting"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MediaType is the media type of CSV documents
+const MediaType = "text/csv"
+
+var ErrInvalidCSV = errors.New("invalid csv")
+
+// ParseError locates an invalid value, lines start at 1 and count the header
+type ParseError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (pe *ParseError) Error() string {
+	if pe.Column == "" {
+		return fmt.Sprintf("line %d: %v", pe.Line, pe.Err)
+	}
+	return fmt.Sprintf("line %d, %s: %v", pe.Line, pe.Column, pe.Err)
+}
+
+// Unwrap matches both ErrInvalidCSV and the cause, e.g. accounting.ErrInvalidAmount
+func (pe *ParseError) Unwrap() []error {
+	return []error{ErrInvalidCSV, pe.Err}
+}
+
+var (
+	transactionColumns = []string{"from", "to", "amount", "currency", "date", "description"}
+	balanceColumns     = []string{"name", "amount", "currency"}
+)
+
+// ReadTransactions parses `from,to,amount[,currency,date,description]` rows
+// a header row, recognized by its first column being `from`, can reorder or omit the optional columns
+// dates, as `2006-01-02` or RFC 3339, are when the transactions occurred
+func ReadTransactions(r io.Reader) (accounting.Transactions, error) {
+	transactions := accounting.Transactions{}
+	err := read(r, transactionColumns, 3, func(row row) error {
+		amount, err := row.amount()
+		if err != nil {
+			return err
+		}
+		occurredAt, err := row.date("date")
+		if err != nil {
+			return err
+		}
+		transactions = append(transactions, accounting.Transaction{
+			From:        row.text("from"),
+			To:          row.text("to"),
+			Amount:      amount,
+			Currency:    row.get("currency"),
+			Description: row.text("description"),
+			OccurredAt:  occurredAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ReadBalances parses `name,amount[,currency]` rows, with an optional header row starting with `name`
+func ReadBalances(r io.Reader) (accounting.Balances, error) {
+	balances := accounting.Balances{}
+	err := read(r, balanceColumns, 2, func(row row) error {
+		amount, err := row.amount()
+		if err != nil {
+			return err
+		}
+		balances = append(balances, accounting.Balance{
+			Name:     row.text("name"),
+			Amount:   amount,
+			Currency: row.get("currency"),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// row is a record with its columns resolved by name
+type row struct {
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+func (r row) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// text is the column value without the `'` written before text starting like a formula
+func (r row) text(column string) string {
+	v := r.get(column)
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
+
+func (r row) amount() (accounting.Money, error) {
+	amount, err := accounting.ParseMoney(r.get("amount"))
+	if err != nil {
+		return accounting.Money{}, &ParseError{Line: r.line, Column: "amount", Err: err}
+	}
+	return amount, nil
+}
+
+func (r row) date(column string) (time.Time, error) {
+	v := r.get(column)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := accounting.ParseTime(v)
+	if err != nil {
+		return time.Time{}, &ParseError{Line: r.line, Column: column, Err: err}
+	}
+	return t, nil
+}
+
+// read calls parse for every record, the columns are positional unless the first record is a header
+func read(r io.Reader, known []string, required int, parse func(row) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	columns := map[string]int{}
+	for i, name := range known {
+		columns[name] = i
+	}
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			return &ParseError{Line: csvErr.Line, Err: csvErr.Err}
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if strings.EqualFold(strings.TrimSpace(record[0]), known[0]) {
+				if columns, err = header(record, known, required); err != nil {
+					return &ParseError{Line: line, Err: err}
+				}
+				continue
+			}
+		}
+
+		if len(record) < required {
+			return &ParseError{Line: line, Err: fmt.Errorf("expected at least %d columns, got %d", required, len(record))}
+		}
+		if len(record) > len(known) {
+			return &ParseError{Line: line, Err: fmt.Errorf("expected at most %d columns, got %d", len(known), len(record))}
+		}
+		if err := parse(row{line: line, columns: columns, record: record}); err != nil {
+			return err
+		}
+	}
+}
+
+// header maps every column name to its position, required columns must be present
+func header(record []string, known []string, required int) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(known, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicated column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range known[:required] {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return columns, nil
+}
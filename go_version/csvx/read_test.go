@@ -0,0 +1,140 @@
+package csvx
+
+import (
+	"bill-splitter/accounting"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Read_Transactions(t *testing.T) {
+	money := accounting.MustParseMoney
+
+	scenarios := []struct {
+		name          string
+		input         string
+		expected      accounting.Transactions
+		expectedError string
+	}{
+		{
+			name:  "should read positional rows with optional columns",
+			input: "A,B,40\n  B , C ,10.5,thb,2026-01-02T10:00:00Z,\"Dinner, drinks\"\n",
+			expected: accounting.Transactions{
+				{From: "A", To: "B", Amount: money("40")},
+				{From: "B", To: "C", Amount: money("10.5"), Currency: "thb", Description: "Dinner, drinks", OccurredAt: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:          "should reject too many columns",
+			input:         "A,B,40\nB,C,10.5,thb,2026-01-02,Dinner,drinks\n",
+			expectedError: "line 2: expected at most 6 columns, got 7",
+		},
+		{
+			name:          "should reject invalid amount with its line",
+			input:         "A,B,40\nB,C,\"1,5\"\n",
+			expectedError: `line 2, amount: invalid amount: "1,5"`,
+		},
+		{
+			name:  "should read header with reordered columns",
+			input: "\ufeffFrom,amount,to,description,date\n# comments are skipped\nA,40,B,Taxi,2026-01-02\n",
+			expected: accounting.Transactions{
+				{From: "A", To: "B", Amount: money("40"), Description: "Taxi", OccurredAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:          "should reject unknown header column",
+			input:         "from,to,amount,category\nA,B,1,food\n",
+			expectedError: `line 1: unknown column "category", expected from, to, amount, currency, date, description`,
+		},
+		{
+			name:          "should reject header without required column",
+			input:         "from,to,currency\n",
+			expectedError: `line 1: missing column "amount"`,
+		},
+		{
+			name:          "should reject missing columns",
+			input:         "A,B,1\nA,B\n",
+			expectedError: "line 2: expected at least 3 columns, got 2",
+		},
+		{
+			name:          "should reject invalid date",
+			input:         "A,B,1,EUR,02/01/2026\n",
+			expectedError: `line 1, date: invalid time: "02/01/2026" is not a date like 2006-01-02 or a time like 2006-01-02T15:04:05Z`,
+		},
+		{
+			name:          "should reject malformed csv",
+			input:         "A,B,1\nA,\"B,1\n",
+			expectedError: "line 2: extraneous or missing \" in quoted-field",
+		},
+		{
+			name:     "should read empty input",
+			input:    "",
+			expected: accounting.Transactions{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := ReadTransactions(strings.NewReader(s.input))
+
+			if s.expectedError != "" {
+				if err == nil || err.Error() != s.expectedError || !errors.Is(err, ErrInvalidCSV) {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Read_Balances(t *testing.T) {
+	money := accounting.MustParseMoney
+
+	scenarios := []struct {
+		name          string
+		input         string
+		expected      accounting.Balances
+		expectedError string
+	}{
+		{
+			name:  "should read rows after header",
+			input: "name,amount,currency\nA,30,EUR\nB,-30,EUR\n",
+			expected: accounting.Balances{
+				{Name: "A", Amount: money("30"), Currency: "EUR"},
+				{Name: "B", Amount: money("-30"), Currency: "EUR"},
+			},
+		},
+		{
+			name:          "should reject invalid amount with its line",
+			input:         "A,30\nB,thirty\n",
+			expectedError: `line 2, amount: invalid amount: "thirty"`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			actual, err := ReadBalances(strings.NewReader(s.input))
+
+			if s.expectedError != "" {
+				if err == nil || err.Error() != s.expectedError || !errors.Is(err, accounting.ErrInvalidAmount) {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if !reflect.DeepEqual(s.expected, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual)
+			}
+		})
+	}
+}
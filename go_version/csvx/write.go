@@ -0,0 +1,51 @@
+package csvx
+
+import (
+	"bill-splitter/accounting"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+// formulaPrefixes are the first characters spreadsheets evaluate a cell from as a formula
+const formulaPrefixes = "=+-@"
+
+// textCell prefixes text starting like a formula with `'`, so spreadsheets show it as text (CSV injection)
+func textCell(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// WriteTransactions writes `from,to,amount,currency,date,description` rows after a header, dates as RFC 3339
+// names and descriptions starting like a formula are prefixed with `'`
+// transactions with several payers are written as one row per contributor
+func WriteTransactions(w io.Writer, transactions accounting.Transactions) error {
+	records := [][]string{{"from", "to", "amount", "currency", "date", "description"}}
+	for _, t := range transactions {
+		date := ""
+		if !t.OccurredAt.IsZero() {
+			date = t.OccurredAt.Format(time.RFC3339)
+		}
+		for _, p := range t.Payments() {
+			records = append(records, []string{textCell(p.From), textCell(p.To), p.Amount.String(), p.Currency, date, textCell(p.Description)})
+		}
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// WriteBalances writes `name,amount,currency` rows after a header, names starting like a formula are prefixed with `'`
+func WriteBalances(w io.Writer, balances accounting.Balances) error {
+	records := [][]string{{"name", "amount", "currency"}}
+	for _, b := range balances {
+		records = append(records, []string{textCell(b.Name), b.Amount.String(), b.Currency})
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// WriteStatement writes the transactions settling the statement, which can be read back by ReadTransactions
+func WriteStatement(w io.Writer, statement accounting.Statement) error {
+	return WriteTransactions(w, statement.Transactions)
+}
@@ -0,0 +1,107 @@
+package csvx
+
+import (
+	"bill-splitter/accounting"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Write(t *testing.T) {
+	money := accounting.MustParseMoney
+
+	scenarios := []struct {
+		name     string
+		write    func(w *strings.Builder) error
+		expected string
+	}{
+		{
+			name: "should write balances",
+			write: func(w *strings.Builder) error {
+				return WriteBalances(w, accounting.Balances{
+					{Name: "A", Amount: money("30"), Currency: "EUR"},
+					{Name: "Smith, John", Amount: money("-30"), Currency: "EUR"},
+				})
+			},
+			expected: "name,amount,currency\nA,30.00,EUR\n\"Smith, John\",-30.00,EUR\n",
+		},
+		{
+			name: "should write statement transactions",
+			write: func(w *strings.Builder) error {
+				return WriteStatement(w, accounting.Statement{
+					UpdatedBalances: accounting.Balances{{Name: "A", Amount: money("0")}},
+					Transactions:    accounting.Transactions{{From: "C", To: "A", Amount: money("30"), Currency: "THB"}},
+				})
+			},
+			expected: "from,to,amount,currency,date,description\nC,A,30.00,THB,,\n",
+		},
+		{
+			name: "should write transactions",
+			write: func(w *strings.Builder) error {
+				return WriteTransactions(w, accounting.Transactions{{From: "A", To: "B", Amount: money("1.5"), Description: "say \"hi\"", OccurredAt: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)}})
+			},
+			expected: "from,to,amount,currency,date,description\nA,B,1.50,,2026-01-02T10:00:00Z,\"say \"\"hi\"\"\"\n",
+		},
+		{
+			name: "should escape text starting like a formula",
+			write: func(w *strings.Builder) error {
+				return WriteTransactions(w, accounting.Transactions{
+					{From: "=A", To: "@B", Amount: money("-1"), Description: "=HYPERLINK(\"http://evil\")"},
+					{From: "+C", To: "-D", Amount: money("1"), Description: "a=b"},
+				})
+			},
+			expected: "from,to,amount,currency,date,description\n'=A,'@B,-1.00,,,\"'=HYPERLINK(\"\"http://evil\"\")\"\n'+C,'-D,1.00,,,a=b\n",
+		},
+		{
+			name: "should escape balance names starting like a formula",
+			write: func(w *strings.Builder) error {
+				return WriteBalances(w, accounting.Balances{{Name: "-A", Amount: money("-30")}})
+			},
+			expected: "name,amount,currency\n'-A,-30.00,\n",
+		},
+		{
+			name: "should write a row per contributor",
+			write: func(w *strings.Builder) error {
+				return WriteTransactions(w, accounting.Transactions{{
+					To: "C", Amount: money("90"), Currency: "EUR", Description: "hotel",
+					Contributors: []accounting.Contribution{{Name: "A", Amount: money("60")}, {Name: "B", Amount: money("30")}},
+				}})
+			},
+			expected: "from,to,amount,currency,date,description\nA,C,60.00,EUR,,hotel\nB,C,30.00,EUR,,hotel\n",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var actual strings.Builder
+			if err := s.write(&actual); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if s.expected != actual.String() {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual.String())
+			}
+		})
+	}
+}
+
+func Test_Write_Read_Round_Trip(t *testing.T) {
+	transactions := accounting.Transactions{
+		{From: "A", To: "B", Amount: accounting.MustParseMoney("10.125"), Currency: "EUR", Description: "Taxi, airport", OccurredAt: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{From: "=B", To: "A", Amount: accounting.MustParseMoney("1"), Description: "+1 beer"},
+	}
+
+	var buf strings.Builder
+	if err := WriteTransactions(&buf, transactions); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	actual, err := ReadTransactions(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if !reflect.DeepEqual(transactions, actual) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", transactions, actual)
+	}
+}
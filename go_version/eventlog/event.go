@@ -0,0 +1,112 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidEvent   = errors.New("invalid event")
+	ErrUnknownExpense = errors.New("unknown expense")
+	ErrCorruptedLog   = errors.New("corrupted event log")
+)
+
+// Type is what happened to a group ledger
+type Type string
+
+const (
+	GroupSaved         Type = "group_saved"
+	ExpenseAdded       Type = "expense_added"
+	ExpenseEdited      Type = "expense_edited"
+	ExpenseDeleted     Type = "expense_deleted"
+	SettlementRecorded Type = "settlement_recorded"
+	SettlementVoided   Type = "settlement_voided"
+)
+
+// Event is an immutable fact of a group ledger, once appended it is never changed
+// Seq orders every event of the log, At is when it was appended and never goes backwards
+type Event struct {
+	Seq     uint64    `json:"seq"`
+	GroupID string    `json:"group_id"`
+	Type    Type      `json:"type"`
+	At      time.Time `json:"at"`
+	// Group is the whole group after being saved
+	Group *accounting.Group `json:"group,omitempty"`
+	// Revision is the transaction revision an expense event records, as the ledger repositories keep it
+	Revision   *accounting.Revision   `json:"revision,omitempty"`
+	Settlement *accounting.Settlement `json:"settlement,omitempty"`
+}
+
+// revisionTypes is the expense event recording each revision action
+var revisionTypes = map[accounting.Action]Type{
+	accounting.ActionCreated: ExpenseAdded,
+	accounting.ActionUpdated: ExpenseEdited,
+	accounting.ActionDeleted: ExpenseDeleted,
+}
+
+// RevisionEvent returns the expense event recording a revision of a group transaction
+func RevisionEvent(groupID string, r accounting.Revision) Event {
+	return Event{GroupID: groupID, Type: revisionTypes[r.Action], Revision: &r}
+}
+
+// SettlementEvent returns the event recording a settlement of the group, or voiding it when it is voided
+func SettlementEvent(groupID string, st accounting.Settlement) Event {
+	if !st.VoidedAt.IsZero() {
+		return Event{GroupID: groupID, Type: SettlementVoided, Settlement: &st}
+	}
+	return Event{GroupID: groupID, Type: SettlementRecorded, Settlement: &st}
+}
+
+// validate checks the event has what its type needs, with amounts and names valid as accounting expects them
+// not whether it applies to the group state
+func (e Event) validate() error {
+	if err := validGroupID(e.GroupID); err != nil {
+		return err
+	}
+
+	switch e.Type {
+	case GroupSaved:
+		if e.Group == nil || e.Group.ID != e.GroupID {
+			return fmt.Errorf("%w: %s needs the group %s", ErrInvalidEvent, e.Type, e.GroupID)
+		}
+	case ExpenseAdded, ExpenseEdited, ExpenseDeleted:
+		if e.Revision == nil || e.Revision.ID == "" {
+			return fmt.Errorf("%w: %s needs a revision with a transaction id", ErrInvalidEvent, e.Type)
+		}
+		if revisionTypes[e.Revision.Action] != e.Type {
+			return fmt.Errorf("%w: %s can not record a %q revision", ErrInvalidEvent, e.Type, e.Revision.Action)
+		}
+		if e.Type == ExpenseDeleted {
+			return nil
+		}
+		if err := accounting.ValidateTransactions(accounting.Transactions{e.Revision.Transaction}, 0); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+	case SettlementRecorded, SettlementVoided:
+		if e.Settlement == nil || e.Settlement.ID == "" {
+			return fmt.Errorf("%w: %s needs a settlement with an id", ErrInvalidEvent, e.Type)
+		}
+		if voided := !e.Settlement.VoidedAt.IsZero(); voided != (e.Type == SettlementVoided) {
+			return fmt.Errorf("%w: only %s settlements have voided_at", ErrInvalidEvent, SettlementVoided)
+		}
+		st := *e.Settlement
+		payment := accounting.Transaction{From: st.From, To: st.To, Amount: st.Amount, Currency: st.Currency}
+		if err := accounting.ValidateTransactions(accounting.Transactions{payment}, 0); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+	return nil
+}
+
+// validGroupID ensures a group id can safely be used as a directory name
+func validGroupID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("%w: invalid group id %q", ErrInvalidEvent, id)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/storage"
+	"context"
+	"errors"
+	"time"
+)
+
+// Ledger is an accounting.LedgerRepository kept in the event log, every change of a group is an event
+// so the group ledgers can be replayed at any point in time
+type Ledger struct {
+	log *Log
+}
+
+func NewLedger(log *Log) *Ledger {
+	return &Ledger{log: log}
+}
+
+// CheckHealth verifies the segments directory still exists and is writable
+func (l *Ledger) CheckHealth(ctx context.Context) error {
+	return storage.CheckWritable(ctx, l.log.store.dir)
+}
+
+// state returns the current state of a saved group, or accounting.ErrGroupNotFound
+func (l *Ledger) state(groupID string) (*State, error) {
+	if validGroupID(groupID) != nil {
+		return nil, accounting.ErrGroupNotFound
+	}
+	state, err := l.log.Replay(groupID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := state.Group(); !ok {
+		return nil, accounting.ErrGroupNotFound
+	}
+	return state, nil
+}
+
+func (l *Ledger) SaveGroup(group accounting.Group) error {
+	if err := validGroupID(group.ID); err != nil {
+		return err
+	}
+	_, err := l.log.Append(Event{GroupID: group.ID, Type: GroupSaved, Group: &group})
+	return err
+}
+
+func (l *Ledger) Group(id string) (accounting.Group, error) {
+	state, err := l.state(id)
+	if err != nil {
+		return accounting.Group{}, err
+	}
+	g, _ := state.Group()
+	return g, nil
+}
+
+func (l *Ledger) Groups() ([]accounting.Group, error) {
+	ids, err := l.log.GroupIDs()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]accounting.Group, 0, len(ids))
+	for _, id := range ids {
+		g, err := l.Group(id)
+		if errors.Is(err, accounting.ErrGroupNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	accounting.SortGroups(groups)
+	return groups, nil
+}
+
+func (l *Ledger) AppendRevisions(groupID string, revisions ...accounting.Revision) error {
+	if _, err := l.state(groupID); err != nil {
+		return err
+	}
+	events := make([]Event, len(revisions))
+	for i, r := range revisions {
+		events[i] = RevisionEvent(groupID, r)
+	}
+	_, err := l.log.Append(events...)
+	return err
+}
+
+func (l *Ledger) Revisions(groupID string) ([]accounting.Revision, error) {
+	state, err := l.state(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return state.Revisions(), nil
+}
+
+func (l *Ledger) AppendSettlements(groupID string, settlements ...accounting.Settlement) error {
+	if _, err := l.state(groupID); err != nil {
+		return err
+	}
+	events := make([]Event, len(settlements))
+	for i, st := range settlements {
+		events[i] = SettlementEvent(groupID, st)
+	}
+	_, err := l.log.Append(events...)
+	return err
+}
+
+func (l *Ledger) Settlements(groupID string) (accounting.Settlements, error) {
+	state, err := l.state(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return state.Settlements(), nil
+}
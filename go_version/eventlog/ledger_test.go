@@ -0,0 +1,109 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Ledger(t *testing.T) {
+	dir := t.TempDir()
+	ctx := accounting.WithActor(context.Background(), "A")
+	service := accounting.NewService(accounting.WithLedger(NewLedger(openLog(t, dir, WithSnapshotEvery(3)))))
+
+	group, err := service.CreateGroup(ctx, accounting.Group{Name: "Bangkok", Currency: "EUR", Members: []accounting.Member{{Name: "A"}, {Name: "B"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	transactions := accounting.Transactions{{From: "A", To: "B", Amount: money("40")}, {From: "B", To: "A", Amount: money("10")}}
+	if err := service.AddTransactions(ctx, group.ID, transactions...); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	amount := money("30")
+	if _, err := service.UpdateTransaction(ctx, group.ID, transactions[0].ID, 1, accounting.TransactionPatch{Amount: &amount}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if _, err := service.RecordSettlement(ctx, group.ID, accounting.Settlement{From: "B", To: "A", Amount: money("5")}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// a new log replays what was appended, from its snapshots
+	reopened := accounting.NewService(accounting.WithLedger(NewLedger(openLog(t, dir))))
+
+	groups, err := reopened.Groups(ctx)
+	if err != nil || len(groups) != 1 || !reflect.DeepEqual(group, groups[0]) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", []accounting.Group{group}, groups, err)
+	}
+	expectedBalances := accounting.Balances{{Name: "A", Amount: money("15"), Currency: "EUR"}, {Name: "B", Amount: money("-15"), Currency: "EUR"}}
+	balances, err := reopened.GroupBalances(ctx, group.ID)
+	if err != nil || !reflect.DeepEqual(expectedBalances, balances) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", expectedBalances, balances, err)
+	}
+
+	if err := reopened.ClearTransactions(ctx, group.ID); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	history, err := reopened.TransactionHistory(ctx, group.ID, transactions[0].ID)
+	if err != nil || len(history) != 3 || history[2].Action != accounting.ActionDeleted {
+		t.Errorf("\nExpected:	created, updated and deleted\nGot:		%+v (%+v)", history, err)
+	}
+	settlements, err := reopened.GroupSettlements(ctx, group.ID)
+	if err != nil || len(settlements) != 1 || settlements[0].VoidedAt.IsZero() {
+		t.Errorf("\nExpected:	one voided settlement\nGot:		%+v (%+v)", settlements, err)
+	}
+}
+
+func Test_Ledger_Not_Found(t *testing.T) {
+	ledger := NewLedger(openLog(t, t.TempDir()))
+
+	scenarios := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "when reading group",
+			call: func() error {
+				_, err := ledger.Group("missing")
+				return err
+			},
+		},
+		{
+			name: "when reading settlements",
+			call: func() error {
+				_, err := ledger.Settlements("missing")
+				return err
+			},
+		},
+		{
+			name: "when appending revisions",
+			call: func() error {
+				return ledger.AppendRevisions("missing", accounting.Revision{Transaction: accounting.Transaction{ID: "t1", Version: 1, From: "A", To: "B"}})
+			},
+		},
+		{
+			name: "when id is not a valid group id",
+			call: func() error {
+				_, err := ledger.Group("../missing")
+				return err
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := s.call(); !errors.Is(err, accounting.ErrGroupNotFound) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", accounting.ErrGroupNotFound, err)
+			}
+		})
+	}
+}
+
+func Test_Ledger_Check_Health(t *testing.T) {
+	ledger := NewLedger(openLog(t, t.TempDir()))
+
+	if err := ledger.CheckHealth(context.Background()); err != nil {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", nil, err)
+	}
+}
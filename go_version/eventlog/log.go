@@ -0,0 +1,238 @@
+package eventlog
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultSnapshotEvery number of events of a group after which its state is snapshotted
+const DefaultSnapshotEvery = 1000
+
+// Log is the append-only event log of every group ledger, events are validated against
+// the group state before being appended and the state can be rebuilt at any point in time
+type Log struct {
+	store         *SegmentStore
+	snapshots     snapshotStore
+	snapshotEvery int
+	now           func() time.Time
+
+	// mu serializes appends, so events are validated against the state they are applied to
+	mu sync.Mutex
+	// pending counts the events appended for each group since its last snapshot
+	pending map[string]int
+	// states caches the current state of the groups replayed since the log was opened
+	states map[string]*State
+	// groupIDs lists the saved groups in the order they were first saved, nil until read
+	groupIDs []string
+}
+
+// Option configures a Log
+type Option func(*logOptions)
+
+type logOptions struct {
+	segmentBytes  int64
+	snapshotEvery int
+}
+
+// WithSegmentBytes sets the size after which events go to a new segment
+func WithSegmentBytes(n int64) Option {
+	return func(o *logOptions) {
+		o.segmentBytes = n
+	}
+}
+
+// WithSnapshotEvery sets how many events of a group are appended before its state is snapshotted
+func WithSnapshotEvery(n int) Option {
+	return func(o *logOptions) {
+		o.snapshotEvery = n
+	}
+}
+
+// Open opens (creating if needed) the log under dir, with segments in `segments` and snapshots in `snapshots`
+func Open(dir string, opts ...Option) (*Log, error) {
+	o := logOptions{segmentBytes: DefaultSegmentBytes, snapshotEvery: DefaultSnapshotEvery}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.segmentBytes <= 0 || o.snapshotEvery <= 0 {
+		return nil, fmt.Errorf("segment bytes and snapshot every must be positive, got %d and %d", o.segmentBytes, o.snapshotEvery)
+	}
+
+	store, err := OpenSegmentStore(filepath.Join(dir, "segments"), o.segmentBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{
+		store:         store,
+		snapshots:     snapshotStore{dir: filepath.Join(dir, "snapshots")},
+		snapshotEvery: o.snapshotEvery,
+		now:           time.Now,
+		pending:       map[string]int{},
+		states:        map[string]*State{},
+	}, nil
+}
+
+// Append validates the events against their group states and appends them all, or none when any is invalid
+// sequence numbers and times are assigned by the log
+func (l *Log) Append(events ...Event) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now().UTC()
+	states := map[string]*State{}
+	seq := l.store.LastSeq()
+	for i := range events {
+		e := &events[i]
+		if err := e.validate(); err != nil {
+			return nil, err
+		}
+		state, ok := states[e.GroupID]
+		if !ok {
+			current, err := l.current(e.GroupID)
+			if err != nil {
+				return nil, err
+			}
+			state = current.clone()
+			states[e.GroupID] = state
+		}
+
+		seq++
+		e.Seq, e.At = seq, now
+		if err := state.apply(*e); err != nil {
+			return nil, err
+		}
+	}
+
+	appended, err := l.store.Append(events...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range appended {
+		l.pending[e.GroupID]++
+		states[e.GroupID].At = e.At
+		if e.Type == GroupSaved && l.groupIDs != nil && !slices.Contains(l.groupIDs, e.GroupID) {
+			l.groupIDs = append(l.groupIDs, e.GroupID)
+		}
+	}
+	for groupID, state := range states {
+		l.states[groupID] = state
+	}
+
+	for groupID, state := range states {
+		if l.pending[groupID] < l.snapshotEvery {
+			continue
+		}
+		// snapshots only speed up replays, the events are already appended when one can not be saved
+		l.pending[groupID] = 0
+		snapshot, err := state.Snapshot()
+		if err == nil {
+			err = l.snapshots.save(snapshot)
+		}
+		if err != nil {
+			slog.Warn("failed to snapshot group", "group_id", groupID, "error", err)
+		}
+	}
+	return appended, nil
+}
+
+// Replay rebuilds the group state with every event appended up to at, or all of them when at is zero
+// it starts from the last snapshot taken before at, and replaying the same events always gives the same state
+func (l *Log) Replay(groupID string, at time.Time) (*State, error) {
+	if at.IsZero() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		state, err := l.current(groupID)
+		if err != nil {
+			return nil, err
+		}
+		return state.clone(), nil
+	}
+	return l.replay(groupID, at)
+}
+
+// current returns the cached state of the group with every event appended, replaying it the first time
+// l.mu must be held, and the state must not be changed
+func (l *Log) current(groupID string) (*State, error) {
+	if state, ok := l.states[groupID]; ok {
+		return state, nil
+	}
+	state, err := l.replay(groupID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	l.states[groupID] = state
+	return state, nil
+}
+
+func (l *Log) replay(groupID string, at time.Time) (*State, error) {
+	if err := validGroupID(groupID); err != nil {
+		return nil, err
+	}
+
+	state := newState(groupID)
+	snapshot, ok, err := l.snapshots.latest(groupID, at)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		state = restore(snapshot)
+	}
+
+	err = l.store.Read(state.Seq+1, func(e Event) error {
+		if !at.IsZero() && e.At.After(at) {
+			// times never go backwards, nothing later can be before at
+			return errStop
+		}
+		if e.GroupID != groupID {
+			return nil
+		}
+		return state.apply(e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Events returns every event of the group, in order
+func (l *Log) Events(groupID string) ([]Event, error) {
+	events := []Event{}
+	err := l.store.Read(1, func(e Event) error {
+		if e.GroupID == groupID {
+			events = append(events, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GroupIDs returns the id of every saved group, in the order they were first saved
+func (l *Log) GroupIDs() ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.groupIDs == nil {
+		ids, seen := []string{}, map[string]bool{}
+		err := l.store.Read(1, func(e Event) error {
+			if e.Type == GroupSaved && !seen[e.GroupID] {
+				seen[e.GroupID] = true
+				ids = append(ids, e.GroupID)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		l.groupIDs = ids
+	}
+	return slices.Clone(l.groupIDs), nil
+}
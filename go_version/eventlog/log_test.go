@@ -0,0 +1,317 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func money(s string) accounting.Money {
+	return accounting.MustParseMoney(s)
+}
+
+func expense(id string, version int, from, to, amount string) accounting.Transaction {
+	return accounting.Transaction{ID: id, Version: version, From: from, To: to, Amount: money(amount), Currency: "EUR"}
+}
+
+// revised returns the event recording the action on the transaction
+func revised(groupID string, action accounting.Action, t accounting.Transaction) Event {
+	return RevisionEvent(groupID, accounting.Revision{Transaction: t, Action: action})
+}
+
+// openLog opens a log whose clock moves one hour on every call
+func openLog(t *testing.T, dir string, opts ...Option) *Log {
+	l, err := Open(dir, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	return l
+}
+
+// history appends one event per hour, from 01:00 to 05:00
+func history(t *testing.T, l *Log) {
+	events := []Event{
+		revised("g1", accounting.ActionCreated, expense("e1", 1, "A", "B", "40")),
+		revised("g1", accounting.ActionCreated, expense("e2", 1, "B", "C", "40")),
+		revised("g1", accounting.ActionUpdated, expense("e2", 2, "B", "C", "10")),
+		revised("g1", accounting.ActionDeleted, expense("e1", 2, "A", "B", "40")),
+		{GroupID: "g1", Type: SettlementRecorded, Settlement: &accounting.Settlement{ID: "s1", From: "C", To: "B", Amount: money("10"), Currency: "EUR"}},
+	}
+	for _, e := range events {
+		if _, err := l.Append(e); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+	}
+	// other groups do not change the state
+	if _, err := l.Append(revised("g2", accounting.ActionCreated, expense("e1", 1, "X", "Y", "1"))); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+}
+
+func Test_Log_Replay(t *testing.T) {
+	l := openLog(t, t.TempDir())
+	history(t, l)
+
+	at := func(hour int) time.Time {
+		return time.Date(2025, 1, 1, hour, 30, 0, 0, time.UTC)
+	}
+	scenarios := []struct {
+		name                 string
+		at                   time.Time
+		expectedBalances     accounting.Balances
+		expectedTransactions int
+	}{
+		{
+			name:             "should replay nothing before the first event",
+			at:               time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedBalances: accounting.Balances{},
+		},
+		{
+			name: "should replay added expenses",
+			at:   at(2),
+			expectedBalances: accounting.Balances{
+				{Name: "A", Amount: money("40"), Currency: "EUR"},
+				{Name: "B", Amount: money("0"), Currency: "EUR"},
+				{Name: "C", Amount: money("-40"), Currency: "EUR"},
+			},
+			expectedTransactions: 2,
+		},
+		{
+			name: "should replay edited expense",
+			at:   at(3),
+			expectedBalances: accounting.Balances{
+				{Name: "A", Amount: money("40"), Currency: "EUR"},
+				{Name: "B", Amount: money("-30"), Currency: "EUR"},
+				{Name: "C", Amount: money("-10"), Currency: "EUR"},
+			},
+			expectedTransactions: 2,
+		},
+		{
+			name: "should replay deleted expense",
+			at:   at(4),
+			expectedBalances: accounting.Balances{
+				{Name: "B", Amount: money("10"), Currency: "EUR"},
+				{Name: "C", Amount: money("-10"), Currency: "EUR"},
+			},
+			expectedTransactions: 1,
+		},
+		{
+			name: "should replay everything",
+			expectedBalances: accounting.Balances{
+				{Name: "B", Amount: money("0"), Currency: "EUR"},
+				{Name: "C", Amount: money("0"), Currency: "EUR"},
+			},
+			expectedTransactions: 1,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			state, err := l.Replay("g1", s.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			balances, err := state.Balances()
+			if err != nil || !reflect.DeepEqual(s.expectedBalances, balances) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", s.expectedBalances, balances, err)
+			}
+			if s.expectedTransactions != len(state.Transactions()) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedTransactions, state.Transactions())
+			}
+		})
+	}
+}
+
+func Test_Log_Replay_Several_Payers(t *testing.T) {
+	l := openLog(t, t.TempDir())
+	hotel := accounting.Transaction{
+		ID: "e1", Version: 1, To: "C", Amount: money("90"), Currency: "EUR",
+		Contributors: []accounting.Contribution{{Name: "A", Amount: money("60")}, {Name: "B", Amount: money("30")}},
+	}
+	deletedHotel := hotel
+	deletedHotel.Version = 2
+	for _, e := range []Event{
+		revised("g1", accounting.ActionCreated, hotel),
+		revised("g1", accounting.ActionDeleted, deletedHotel),
+	} {
+		if _, err := l.Append(e); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+	}
+
+	scenarios := []struct {
+		name             string
+		at               time.Time
+		expectedBalances accounting.Balances
+	}{
+		{
+			name: "should move every contribution",
+			at:   time.Date(2025, 1, 1, 1, 30, 0, 0, time.UTC),
+			expectedBalances: accounting.Balances{
+				{Name: "A", Amount: money("60"), Currency: "EUR"},
+				{Name: "B", Amount: money("30"), Currency: "EUR"},
+				{Name: "C", Amount: money("-90"), Currency: "EUR"},
+			},
+		},
+		{
+			name:             "should revert every contribution",
+			expectedBalances: accounting.Balances{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			state, err := l.Replay("g1", s.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			balances, err := state.Balances()
+			if err != nil || !reflect.DeepEqual(s.expectedBalances, balances) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", s.expectedBalances, balances, err)
+			}
+		})
+	}
+}
+
+func Test_Log_Replay_From_Snapshots(t *testing.T) {
+	for _, at := range []time.Time{{}, time.Date(2025, 1, 1, 3, 30, 0, 0, time.UTC)} {
+		dir := t.TempDir()
+		history(t, openLog(t, dir, WithSnapshotEvery(2)))
+
+		snapshots, _ := filepath.Glob(filepath.Join(dir, "snapshots", "g1", "*.json"))
+		if len(snapshots) != 2 {
+			t.Errorf("\nExpected:	%+v\nGot:		%+v", 2, snapshots)
+		}
+
+		fromSnapshot, err := openLog(t, dir).Replay("g1", at)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		if err := os.RemoveAll(filepath.Join(dir, "snapshots")); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		fromScratch, err := openLog(t, dir).Replay("g1", at)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+
+		expected, _ := fromScratch.Snapshot()
+		actual, err := fromSnapshot.Snapshot()
+		if err != nil || !reflect.DeepEqual(expected, actual) {
+			t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", expected, actual, err)
+		}
+	}
+}
+
+func Test_Log_Append_Snapshot_Failure(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, WithSnapshotEvery(2))
+	if _, err := l.Append(revised("g1", accounting.ActionCreated, expense("e1", 1, "A", "B", "40"))); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	// the snapshot of the next event can not replace a directory
+	blocked := filepath.Join(dir, "snapshots", "g1", segmentName(2, snapshotExt))
+	if err := os.MkdirAll(blocked, 0o755); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	appended, err := l.Append(revised("g1", accounting.ActionCreated, expense("e2", 1, "B", "C", "10")))
+	if err != nil || len(appended) != 1 {
+		t.Fatalf("\nExpected:	%+v\nGot:		%+v (%+v)", 1, appended, err)
+	}
+	if l.pending["g1"] != 0 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", 0, l.pending["g1"])
+	}
+
+	if err := os.Remove(blocked); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	state, err := openLog(t, dir).Replay("g1", time.Time{})
+	if err != nil || len(state.Transactions()) != 2 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", 2, state, err)
+	}
+}
+
+func Test_Log_Append_Errors(t *testing.T) {
+	l := openLog(t, t.TempDir())
+	history(t, l)
+
+	scenarios := []struct {
+		name          string
+		events        []Event
+		expectedError error
+	}{
+		{
+			name:          "when editing unknown expense",
+			events:        []Event{revised("g1", accounting.ActionUpdated, expense("e9", 2, "A", "B", "1"))},
+			expectedError: ErrUnknownExpense,
+		},
+		{
+			name:          "when deleting deleted expense",
+			events:        []Event{revised("g1", accounting.ActionDeleted, expense("e1", 3, "A", "B", "40"))},
+			expectedError: ErrUnknownExpense,
+		},
+		{
+			name:          "when invalid amount",
+			events:        []Event{revised("g1", accounting.ActionCreated, expense("e5", 1, "A", "B", "-1"))},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name:          "when invalid settlement",
+			events:        []Event{{GroupID: "g1", Type: SettlementRecorded, Settlement: &accounting.Settlement{ID: "s2", From: "C", Amount: money("1")}}},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name:          "when skipping a version",
+			events:        []Event{revised("g1", accounting.ActionUpdated, expense("e2", 4, "B", "C", "1"))},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name: "when adding an existing expense after valid events",
+			events: []Event{
+				revised("g1", accounting.ActionCreated, expense("e3", 1, "A", "B", "1")),
+				revised("g1", accounting.ActionCreated, expense("e3", 1, "A", "B", "1")),
+			},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name:          "when revision does not match the type",
+			events:        []Event{{GroupID: "g1", Type: ExpenseAdded, Revision: &accounting.Revision{Transaction: expense("e4", 1, "A", "B", "1"), Action: accounting.ActionDeleted}}},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name:          "when unknown type",
+			events:        []Event{{GroupID: "g1", Type: "expense_renamed", Revision: &accounting.Revision{Transaction: expense("e2", 3, "B", "C", "1")}}},
+			expectedError: ErrInvalidEvent,
+		},
+		{
+			name:          "when invalid group id",
+			events:        []Event{revised("../g1", accounting.ActionDeleted, expense("e2", 3, "B", "C", "10"))},
+			expectedError: ErrInvalidEvent,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := l.Append(s.events...)
+
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if seq := l.store.LastSeq(); seq != 6 {
+				t.Errorf("expected nothing to be appended, got last event %d", seq)
+			}
+		})
+	}
+}
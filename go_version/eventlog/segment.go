@@ -0,0 +1,234 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	segmentExt = ".log"
+	// DefaultSegmentBytes size after which appends go to a new segment
+	DefaultSegmentBytes int64 = 16 << 20
+	maxEventBytes             = 1 << 20
+)
+
+// SegmentStore is an append-only log of events on disk, split in JSON lines segment files named after
+// the sequence number of their first event, only the last segment is ever written
+type SegmentStore struct {
+	dir          string
+	segmentBytes int64
+
+	mu sync.RWMutex
+	// segments holds the first sequence number of every segment, in order
+	segments []uint64
+	// size of the last segment
+	size   int64
+	lastAt time.Time
+	next   uint64
+}
+
+// OpenSegmentStore opens (creating if needed) the segments under dir
+// an incomplete last event, left by a crash in the middle of an append, is discarded
+func OpenSegmentStore(dir string, segmentBytes int64) (*SegmentStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+
+	ss := &SegmentStore{dir: dir, segmentBytes: segmentBytes, next: 1}
+	for _, e := range entries {
+		if seq, ok := parseSegmentName(e.Name(), segmentExt); ok {
+			ss.segments = append(ss.segments, seq)
+		}
+	}
+	slices.Sort(ss.segments)
+
+	if len(ss.segments) > 0 {
+		if err := ss.recover(); err != nil {
+			return nil, err
+		}
+	}
+	return ss, nil
+}
+
+// recover finds where the last segment ends, truncating an incomplete last line
+func (ss *SegmentStore) recover() error {
+	first := ss.segments[len(ss.segments)-1]
+	path := ss.segmentPath(first)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read segment: %w", err)
+	}
+
+	ss.next = first
+	offset := 0
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			break
+		}
+		var e Event
+		if err := json.Unmarshal(data[offset:offset+end], &e); err != nil {
+			return fmt.Errorf("%w: segment %s at byte %d: %v", ErrCorruptedLog, path, offset, err)
+		}
+		if e.Seq != ss.next {
+			return fmt.Errorf("%w: segment %s has event %d where %d was expected", ErrCorruptedLog, path, e.Seq, ss.next)
+		}
+		ss.next, ss.lastAt = e.Seq+1, e.At
+		offset += end + 1
+	}
+
+	if offset < len(data) {
+		if err := os.Truncate(path, int64(offset)); err != nil {
+			return fmt.Errorf("failed to discard incomplete event: %w", err)
+		}
+	}
+	ss.size = int64(offset)
+
+	// the last event time is needed to keep times in order, it may be in the previous segment
+	if ss.next == first && len(ss.segments) > 1 {
+		return ss.Read(ss.segments[len(ss.segments)-2], func(e Event) error {
+			ss.lastAt = e.At
+			return nil
+		})
+	}
+	return nil
+}
+
+func (ss *SegmentStore) segmentPath(first uint64) string {
+	return filepath.Join(ss.dir, segmentName(first, segmentExt))
+}
+
+// LastSeq returns the sequence number of the last event, 0 when the log is empty
+func (ss *SegmentStore) LastSeq() uint64 {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.next - 1
+}
+
+// Append assigns the next sequence numbers to the events and writes them at the end of the log
+// times before the last event are moved forward to it, so replaying up to a time is consistent with the order
+func (ss *SegmentStore) Append(events ...Event) ([]Event, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	appended := make([]Event, len(events))
+	var buf []byte
+	for i, e := range events {
+		e.Seq = ss.next + uint64(i)
+		if e.At.Before(ss.lastAt) {
+			e.At = ss.lastAt
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event: %w", err)
+		}
+		if len(line) >= maxEventBytes {
+			return nil, fmt.Errorf("%w: event is larger than %d bytes", ErrInvalidEvent, maxEventBytes)
+		}
+		buf = append(append(buf, line...), '\n')
+		appended[i] = e
+	}
+	if len(appended) == 0 {
+		return appended, nil
+	}
+
+	if len(ss.segments) == 0 || (ss.size > 0 && ss.size+int64(len(buf)) > ss.segmentBytes) {
+		ss.segments = append(ss.segments, ss.next)
+		ss.size = 0
+	}
+
+	f, err := os.OpenFile(ss.segmentPath(ss.segments[len(ss.segments)-1]), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to append to segment: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to sync segment: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close segment: %w", err)
+	}
+
+	last := appended[len(appended)-1]
+	ss.size += int64(len(buf))
+	ss.next, ss.lastAt = last.Seq+1, last.At
+	return appended, nil
+}
+
+// errStop ends a Read early without failing it
+var errStop = errors.New("stop reading")
+
+// Read calls fn for every event from the sequence number on, in order, segments before it are skipped
+// fn must not append to the store, and returning errStop ends the read without error
+func (ss *SegmentStore) Read(from uint64, fn func(Event) error) error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	start := 0
+	for i, first := range ss.segments {
+		if first <= from {
+			start = i
+		}
+	}
+	for _, first := range ss.segments[start:] {
+		if err := ss.readSegment(first, from, fn); err != nil {
+			if errors.Is(err, errStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *SegmentStore) readSegment(first, from uint64, fn func(Event) error) error {
+	f, err := os.Open(ss.segmentPath(first))
+	if err != nil {
+		return fmt.Errorf("failed to open segment: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(f, ss.sizeOf(first)))
+	scanner.Buffer(make([]byte, 64*1024), maxEventBytes)
+	for line := 1; scanner.Scan(); line++ {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("%w: segment %d at line %d: %v", ErrCorruptedLog, first, line, err)
+		}
+		if e.Seq < from {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read segment: %w", err)
+	}
+	return nil
+}
+
+// sizeOf limits reads of the last segment to what was appended, older segments are complete
+func (ss *SegmentStore) sizeOf(first uint64) int64 {
+	if first == ss.segments[len(ss.segments)-1] {
+		return ss.size
+	}
+	return 1<<63 - 1
+}
@@ -0,0 +1,79 @@
+package eventlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Segment_Store(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenSegmentStore(dir, 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 6 {
+		e := Event{GroupID: "g1", Type: ExpenseDeleted, At: start.Add(time.Duration(i) * time.Hour)}
+		if _, err := store.Append(e); err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+	}
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	if len(segments) < 2 {
+		t.Errorf("expected events to roll over several segments, got %+v", segments)
+	}
+
+	// a crash in the middle of an append leaves an incomplete line
+	last := segments[len(segments)-1]
+	f, _ := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0o644)
+	_, _ = f.WriteString(`{"seq":7,"group_id":"g1","ty`)
+	_ = f.Close()
+
+	reopened, err := OpenSegmentStore(dir, 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if seq := reopened.LastSeq(); seq != 6 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", 6, seq)
+	}
+
+	// times never go backwards
+	appended, err := reopened.Append(Event{GroupID: "g1", Type: ExpenseDeleted, At: start})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if appended[0].Seq != 7 || !appended[0].At.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("expected event 7 at the time of event 6, got %+v", appended[0])
+	}
+
+	var seqs []uint64
+	err = reopened.Read(3, func(e Event) error {
+		seqs = append(seqs, e.Seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	expected := []uint64{3, 4, 5, 6, 7}
+	if !reflect.DeepEqual(expected, seqs) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, seqs)
+	}
+}
+
+func Test_Segment_Store_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	segment := filepath.Join(dir, segmentName(1, segmentExt))
+	if err := os.WriteFile(segment, []byte("not json\n{}\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if _, err := OpenSegmentStore(dir, DefaultSegmentBytes); !errors.Is(err, ErrCorruptedLog) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", ErrCorruptedLog, err)
+	}
+}
@@ -0,0 +1,131 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const snapshotExt = ".json"
+
+// Snapshot is a group state at an event, with its computed balances, so replays do not start from scratch
+// the balances are kept for readers of the snapshot files
+type Snapshot struct {
+	GroupID     string                 `json:"group_id"`
+	Seq         uint64                 `json:"seq"`
+	At          time.Time              `json:"at"`
+	Group       *accounting.Group      `json:"group,omitempty"`
+	Revisions   []accounting.Revision  `json:"revisions"`
+	Settlements accounting.Settlements `json:"settlements"`
+	Balances    accounting.Balances    `json:"balances"`
+}
+
+// Snapshot captures the state as it is after its last event
+func (s *State) Snapshot() (Snapshot, error) {
+	balances, err := s.Balances()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{
+		GroupID:     s.GroupID,
+		Seq:         s.Seq,
+		At:          s.At,
+		Revisions:   s.Revisions(),
+		Settlements: s.Settlements(),
+		Balances:    balances,
+	}
+	if g, ok := s.Group(); ok {
+		snapshot.Group = &g
+	}
+	return snapshot, nil
+}
+
+// restore rebuilds the state a snapshot was taken from, balances are calculated again from the revisions
+func restore(snapshot Snapshot) *State {
+	s := newState(snapshot.GroupID)
+	s.Seq, s.At, s.group = snapshot.Seq, snapshot.At, snapshot.Group
+	for _, r := range snapshot.Revisions {
+		s.record(r)
+	}
+	s.settlements = append(s.settlements, snapshot.Settlements...)
+	return s
+}
+
+// snapshotStore keeps every snapshot of a group in its directory, one file per snapshot named after its sequence number
+type snapshotStore struct {
+	dir string
+}
+
+func (ss snapshotStore) groupDir(groupID string) string {
+	return filepath.Join(ss.dir, groupID)
+}
+
+func (ss snapshotStore) save(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	dir := ss.groupDir(snapshot.GroupID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return storage.WriteFileAtomic(filepath.Join(dir, segmentName(snapshot.Seq, snapshotExt)), data)
+}
+
+// latest returns the last snapshot taken at or before at, any time when at is zero
+func (ss snapshotStore) latest(groupID string, at time.Time) (Snapshot, bool, error) {
+	entries, err := os.ReadDir(ss.groupDir(groupID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var seqs []uint64
+	for _, e := range entries {
+		if seq, ok := parseSegmentName(e.Name(), snapshotExt); ok {
+			seqs = append(seqs, seq)
+		}
+	}
+	slices.Sort(seqs)
+
+	for i := len(seqs) - 1; i >= 0; i-- {
+		path := filepath.Join(ss.groupDir(groupID), segmentName(seqs[i], snapshotExt))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Snapshot{}, false, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return Snapshot{}, false, fmt.Errorf("%w: snapshot %s: %v", ErrCorruptedLog, path, err)
+		}
+		if at.IsZero() || !snapshot.At.After(at) {
+			return snapshot, true, nil
+		}
+	}
+	return Snapshot{}, false, nil
+}
+
+// segmentName names files after a sequence number, zero padded so they sort like numbers
+func segmentName(seq uint64, ext string) string {
+	return fmt.Sprintf("%020d%s", seq, ext)
+}
+
+func parseSegmentName(name, ext string) (uint64, bool) {
+	digits, ok := strings.CutSuffix(name, ext)
+	if !ok || len(digits) != 20 {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(digits, 10, 64)
+	return seq, err == nil
+}
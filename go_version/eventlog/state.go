@@ -0,0 +1,141 @@
+package eventlog
+
+import (
+	"bill-splitter/accounting"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// State is a group ledger rebuilt from its events
+type State struct {
+	GroupID string
+	// Seq and At are the ones of the last applied event
+	Seq uint64
+	At  time.Time
+
+	// group is nil until the group is saved
+	group       *accounting.Group
+	revisions   []accounting.Revision
+	settlements accounting.Settlements
+	// latest indexes the last revision of every transaction
+	latest map[string]int
+}
+
+func newState(groupID string) *State {
+	return &State{
+		GroupID:     groupID,
+		revisions:   []accounting.Revision{},
+		settlements: accounting.Settlements{},
+		latest:      map[string]int{},
+	}
+}
+
+// Group returns the group as it was last saved, false when it never was
+func (s *State) Group() (accounting.Group, bool) {
+	if s.group == nil {
+		return accounting.Group{}, false
+	}
+	g := *s.group
+	g.Members = slices.Clone(g.Members)
+	return g, true
+}
+
+// Revisions returns every transaction revision in the order they were recorded
+func (s *State) Revisions() []accounting.Revision {
+	return slices.Clone(s.revisions)
+}
+
+// Transactions returns the current version of every transaction not deleted, in the order they were added
+func (s *State) Transactions() accounting.Transactions {
+	return accounting.CurrentTransactions(s.revisions)
+}
+
+// Settlements returns the recorded settlements in the order they were recorded, voided ones are recorded again
+func (s *State) Settlements() accounting.Settlements {
+	return slices.Clone(s.settlements)
+}
+
+// Balances returns the balance of everyone in the ledger per currency, as accounting calculates them
+// converting them is up to the accounting service
+func (s *State) Balances() (accounting.Balances, error) {
+	return accounting.LedgerBalances(s.revisions, s.settlements)
+}
+
+// clone copies the state, so events can be tried without changing it
+func (s *State) clone() *State {
+	c := *s
+	c.revisions = slices.Clone(s.revisions)
+	c.settlements = slices.Clone(s.settlements)
+	c.latest = maps.Clone(s.latest)
+	return &c
+}
+
+// apply changes the state with the next event of the group, events must come in sequence order
+// revisions must follow the last one of their transaction, added with version 1 and deleted ones never changed again
+func (s *State) apply(e Event) error {
+	if e.GroupID != s.GroupID {
+		return fmt.Errorf("%w: event %d belongs to group %s, not %s", ErrInvalidEvent, e.Seq, e.GroupID, s.GroupID)
+	}
+	if e.Seq <= s.Seq {
+		return fmt.Errorf("%w: event %d comes after event %d", ErrInvalidEvent, e.Seq, s.Seq)
+	}
+
+	switch e.Type {
+	case GroupSaved:
+		g := *e.Group
+		s.group = &g
+	case ExpenseAdded:
+		r := *e.Revision
+		if _, ok := s.latest[r.ID]; ok {
+			return fmt.Errorf("%w: expense %s already exists", ErrInvalidEvent, r.ID)
+		}
+		if r.Version != 1 {
+			return fmt.Errorf("%w: expense %s must be added with version 1, not %d", ErrInvalidEvent, r.ID, r.Version)
+		}
+		s.record(r)
+	case ExpenseEdited, ExpenseDeleted:
+		r := *e.Revision
+		i, ok := s.latest[r.ID]
+		if !ok || s.revisions[i].Action == accounting.ActionDeleted {
+			return fmt.Errorf("%w: %s", ErrUnknownExpense, r.ID)
+		}
+		previous := s.revisions[i]
+		if r.Version != previous.Version+1 {
+			return fmt.Errorf("%w: expense %s is at version %d, not %d", ErrInvalidEvent, r.ID, previous.Version, r.Version-1)
+		}
+		s.record(r)
+	case SettlementRecorded:
+		if _, ok := s.settlement(e.Settlement.ID); ok {
+			return fmt.Errorf("%w: settlement %s already exists", ErrInvalidEvent, e.Settlement.ID)
+		}
+		s.settlements = append(s.settlements, *e.Settlement)
+	case SettlementVoided:
+		if st, ok := s.settlement(e.Settlement.ID); !ok || !st.VoidedAt.IsZero() {
+			return fmt.Errorf("%w: settlement %s is not recorded or already voided", ErrInvalidEvent, e.Settlement.ID)
+		}
+		s.settlements = append(s.settlements, *e.Settlement)
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+
+	s.Seq, s.At = e.Seq, e.At
+	return nil
+}
+
+// record appends the revision, making it the last one of its transaction
+func (s *State) record(r accounting.Revision) {
+	s.latest[r.ID] = len(s.revisions)
+	s.revisions = append(s.revisions, r)
+}
+
+// settlement returns the last record of a settlement
+func (s *State) settlement(id string) (accounting.Settlement, bool) {
+	for i := len(s.settlements) - 1; i >= 0; i-- {
+		if s.settlements[i].ID == id {
+			return s.settlements[i], true
+		}
+	}
+	return accounting.Settlement{}, false
+}
@@ -0,0 +1,326 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/csvx"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const jsonMediaType = "application/json"
+
+// codec reads and writes bodies of a media type
+type codec struct {
+	// contentType is written in the `Content-Type` header of responses
+	contentType string
+	// supports reports if v, or what it points to, has a representation in the media type
+	supports func(v any) bool
+	decode   func(r io.Reader, v any) error
+	encode   func(w io.Writer, v any) error
+}
+
+// codecs is the registry of supported media types, JSON is the default one
+var codecs = map[string]codec{
+	jsonMediaType: {
+		contentType: jsonMediaType,
+		supports:    func(any) bool { return true },
+		decode:      decodeJSON,
+		encode:      func(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) },
+	},
+	csvx.MediaType: {
+		contentType: csvx.MediaType + "; charset=utf-8",
+		supports:    supportsCSV,
+		decode:      decodeCSV,
+		encode:      encodeCSV,
+	},
+}
+
+var errNotAcceptable = newHttpError(
+	kindNotAcceptable,
+	"not_acceptable",
+	errors.New("Invalid `Accept` header. No supported media type is acceptable"),
+)
+
+// validateContentType handler to just validate if the request has the correct content type
+// JSON is always accepted, besides any of the given media types, parameters other than a UTF-8 charset are ignored
+func validateContentType(next customHandler, mediaTypes ...string) customHandler {
+	mediaTypes = append([]string{jsonMediaType}, mediaTypes...)
+	invalid := invalidContentType
+	if len(mediaTypes) > 1 {
+		invalid = newHttpError(
+			kindUnsupportedMediaType,
+			"unsupported_media_type",
+			fmt.Errorf("Invalid `Content-Type` header. Expected `%s`", strings.Join(mediaTypes, "` or `")),
+		)
+	}
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		mediaType, params, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if err != nil || !slices.Contains(mediaTypes, mediaType) {
+			return invalid
+		}
+		if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+			return newHttpError(
+				kindUnsupportedMediaType,
+				"unsupported_media_type",
+				fmt.Errorf("Invalid `Content-Type` header. Unsupported charset %q, expected `utf-8`", charset),
+			)
+		}
+
+		if next != nil {
+			return next(writer, request)
+		}
+		return nil
+	}
+}
+
+// readBody decodes the request body into v with the codec of its `Content-Type`, closing it afterward
+// a missing or unknown `Content-Type` is read as JSON, validateContentType rejects them beforehand
+func readBody(request *http.Request, v any) error {
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.WarnContext(request.Context(), "failed to close request body", "error", err)
+		}
+	}(request.Body)
+
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	c, ok := codecs[mediaType]
+	if !ok {
+		mediaType, c = jsonMediaType, codecs[jsonMediaType]
+	}
+	if !c.supports(v) {
+		return newHttpError(
+			kindUnsupportedMediaType,
+			"unsupported_media_type",
+			fmt.Errorf("Invalid `Content-Type` header. `%s` is not supported by %s", mediaType, request.URL.Path),
+		)
+	}
+	return c.decode(request.Body, v)
+}
+
+// writeBody writes v with the codec negotiated from the `Accept` header, headers and status must come before the body
+func writeBody(writer http.ResponseWriter, request *http.Request, status int, v any) error {
+	c, err := negotiate(request.Header.Get("Accept"), v)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Content-Type", c.contentType)
+	writer.WriteHeader(status)
+	if err := c.encode(writer, v); err != nil {
+		return fmt.Errorf("failed to write response body: %+v", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as JSON regardless of the `Accept` header, meant for the operational endpoints
+func writeJSON(writer http.ResponseWriter, status int, v any) error {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(v); err != nil {
+		return fmt.Errorf("failed to write response body: %+v", err)
+	}
+	return nil
+}
+
+// negotiate picks the codec of the media type with the highest quality in the `Accept` header able to encode v
+// ties keep the header order, wildcards prefer JSON and a missing header means JSON
+func negotiate(accept string, v any) (codec, error) {
+	if strings.TrimSpace(accept) == "" {
+		return codecs[jsonMediaType], nil
+	}
+
+	type acceptable struct {
+		pattern string
+		quality float64
+	}
+	var ranges []acceptable
+	for _, part := range strings.Split(accept, ",") {
+		pattern, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, acceptable{pattern: pattern, quality: quality})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b acceptable) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	// JSON first, then the others in a stable order
+	mediaTypes := slices.Sorted(maps.Keys(codecs))
+	mediaTypes = append([]string{jsonMediaType}, slices.DeleteFunc(mediaTypes, func(m string) bool { return m == jsonMediaType })...)
+	for _, r := range ranges {
+		for _, mediaType := range mediaTypes {
+			if c := codecs[mediaType]; matchMediaType(r.pattern, mediaType) && c.supports(v) {
+				return c, nil
+			}
+		}
+	}
+	return codec{}, errNotAcceptable
+}
+
+// matchMediaType reports if the media type is in the range, e.g. `*/*`, `text/*` or `text/csv`
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// decodeJSON decodes a JSON body, size limits are returned as is, amounts that do not parse are validation errors
+// with the index and field they are found at, any other failure is a malformed body
+func decodeJSON(r io.Reader, v any) error {
+	var raw json.RawMessage
+	err := json.NewDecoder(r).Decode(&raw)
+	if err == nil {
+		if err = json.Unmarshal(raw, v); err != nil {
+			if fieldErrors := amountErrors(reflect.TypeOf(v), raw); len(fieldErrors) > 0 {
+				return &accounting.ValidationError{Errors: fieldErrors}
+			}
+		}
+	}
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return maxBytesErr
+		}
+		return newHttpError(kindBadRequest, "malformed_body", fmt.Errorf("failed to read request body: %+v", err))
+	}
+	return nil
+}
+
+var moneyType = reflect.TypeFor[accounting.Money]()
+
+// amountErrors finds every amount of the body that does not parse, one per list item, index is -1 for objects
+func amountErrors(t reflect.Type, raw json.RawMessage) []accounting.FieldError {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var items []json.RawMessage
+	if t.Kind() != reflect.Slice || json.Unmarshal(raw, &items) != nil {
+		if field, err := amountField(t, raw); err != nil {
+			return []accounting.FieldError{amountError(-1, field, err)}
+		}
+		return nil
+	}
+
+	var fieldErrors []accounting.FieldError
+	for i, item := range items {
+		if field, err := amountField(t.Elem(), item); err != nil {
+			fieldErrors = append(fieldErrors, amountError(i, field, err))
+		}
+	}
+	return fieldErrors
+}
+
+// amountField returns the path, e.g. `items[1].amount`, and the error of the first amount that does not parse
+func amountField(t reflect.Type, raw json.RawMessage) (string, error) {
+	for t.Kind() == reflect.Pointer {
+		if string(raw) == "null" {
+			return "", nil
+		}
+		t = t.Elem()
+	}
+
+	switch {
+	case t == moneyType:
+		var m accounting.Money
+		return "", json.Unmarshal(raw, &m)
+	case t.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return "", nil
+		}
+		for i, item := range items {
+			if path, err := amountField(t.Elem(), item); err != nil {
+				return joinPath(fmt.Sprintf("[%d]", i), path), err
+			}
+		}
+	case t.Kind() == reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil {
+			return "", nil
+		}
+		for _, f := range reflect.VisibleFields(t) {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			value, ok := fields[name]
+			if f.Anonymous || !f.IsExported() || name == "" || name == "-" || !ok {
+				continue
+			}
+			if path, err := amountField(f.Type, value); err != nil {
+				return joinPath(name, path), err
+			}
+		}
+	}
+	return "", nil
+}
+
+func joinPath(name, path string) string {
+	if path == "" || strings.HasPrefix(path, "[") {
+		return name + path
+	}
+	return name + "." + path
+}
+
+func amountError(index int, field string, err error) accounting.FieldError {
+	message := fmt.Sprintf("must be a decimal number with up to %d decimal places", accounting.MaxScale)
+	if errors.Is(err, accounting.ErrAmountTooLarge) {
+		message = "must not be above " + accounting.MaxAmount.String()
+	}
+	return accounting.FieldError{Index: index, Field: field, Message: message}
+}
+
+// supportsCSV only transactions, balances and statements are tabular
+func supportsCSV(v any) bool {
+	switch v.(type) {
+	case *accounting.Transactions, *accounting.Balances,
+		accounting.Transactions, accounting.Balances, accounting.Statement:
+		return true
+	}
+	return false
+}
+
+func decodeCSV(r io.Reader, v any) error {
+	var err error
+	switch t := v.(type) {
+	case *accounting.Transactions:
+		*t, err = csvx.ReadTransactions(r)
+	case *accounting.Balances:
+		*t, err = csvx.ReadBalances(r)
+	default:
+		err = fmt.Errorf("no csv representation for %T", v)
+	}
+	return err
+}
+
+func encodeCSV(w io.Writer, v any) error {
+	switch t := v.(type) {
+	case accounting.Transactions:
+		return csvx.WriteTransactions(w, t)
+	case accounting.Balances:
+		return csvx.WriteBalances(w, t)
+	case accounting.Statement:
+		return csvx.WriteStatement(w, t)
+	}
+	return fmt.Errorf("no csv representation for %T", v)
+}
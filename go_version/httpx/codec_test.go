@@ -0,0 +1,196 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Negotiate(t *testing.T) {
+	scenarios := []struct {
+		name                string
+		accept              string
+		value               any
+		expectedContentType string
+		expectedCode        string
+	}{
+		{
+			name:                "when no accept header",
+			value:               accounting.Balances{},
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "when any media type",
+			accept:              "*/*",
+			value:               accounting.Balances{},
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "when csv is preferred",
+			accept:              "application/json;q=0.5, text/csv",
+			value:               accounting.Statement{},
+			expectedContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:                "when text range",
+			accept:              "text/*",
+			value:               accounting.Transactions{},
+			expectedContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:                "when csv is not supported for the value",
+			accept:              "text/csv, application/json;q=0.1",
+			value:               accounting.Group{},
+			expectedContentType: "application/json",
+		},
+		{
+			name:         "when nothing is acceptable",
+			accept:       "text/html, application/json;q=0",
+			value:        accounting.Balances{},
+			expectedCode: "not_acceptable",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			c, err := negotiate(s.accept, s.value)
+
+			if s.expectedCode != "" {
+				if err == nil || classify(err).code != s.expectedCode {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if s.expectedContentType != c.contentType {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedContentType, c.contentType)
+			}
+		})
+	}
+}
+
+func Test_Read_Body(t *testing.T) {
+	scenarios := []struct {
+		name         string
+		contentType  string
+		body         string
+		value        any
+		expectedCode string
+	}{
+		{
+			name:        "when json",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"from":"A","to":"B","amount":1}]`,
+			value:       &accounting.Transactions{},
+		},
+		{
+			name:        "when csv",
+			contentType: "text/csv",
+			body:        "A,B,1\n",
+			value:       &accounting.Transactions{},
+		},
+		{
+			name:         "when csv is not supported for the value",
+			contentType:  "text/csv",
+			body:         "A,B,1\n",
+			value:        &accounting.Expenses{},
+			expectedCode: "unsupported_media_type",
+		},
+		{
+			name:         "when invalid csv",
+			contentType:  "text/csv",
+			body:         "A\n",
+			value:        &accounting.Balances{},
+			expectedCode: "invalid_csv",
+		},
+		{
+			name:         "when malformed json",
+			contentType:  "application/json",
+			body:         "[",
+			value:        &accounting.Balances{},
+			expectedCode: "malformed_body",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/any", strings.NewReader(s.body))
+			request.Header.Set("Content-Type", s.contentType)
+
+			err := readBody(request, s.value)
+
+			if s.expectedCode != "" {
+				if err == nil || classify(err).code != s.expectedCode {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+		})
+	}
+}
+
+func Test_Read_Body_Amount_Errors(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		body     string
+		value    any
+		expected string
+	}{
+		{
+			name:     "when amount is not a number",
+			body:     `[{"from":"A","to":"B","amount":40},{"from":"A","to":"B","amount":"NaN"}]`,
+			value:    &accounting.Transactions{},
+			expected: "validation failed: [1].amount must be a decimal number with up to 6 decimal places",
+		},
+		{
+			name:     "when amount is null",
+			body:     `[{"from":"A","to":"B","amount":null}]`,
+			value:    &accounting.Transactions{},
+			expected: "validation failed: [0].amount must be a decimal number with up to 6 decimal places",
+		},
+		{
+			name:     "when amount is too large",
+			body:     `[{"name":"A","amount":1e13}]`,
+			value:    &accounting.Balances{},
+			expected: "validation failed: [0].amount must not be above 1000000000000",
+		},
+		{
+			name:     "when nested amount is invalid",
+			body:     `{"paid_by":[{"name":"A","amount":10}],"items":[{"amount":"ten","consumers":["A"]}]}`,
+			value:    &accounting.Receipt{},
+			expected: "validation failed: items[0].amount must be a decimal number with up to 6 decimal places",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/any", strings.NewReader(s.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			err := readBody(request, s.value)
+
+			if err == nil || err.Error() != s.expected || classify(err).code != "validation_failed" {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, err)
+			}
+		})
+	}
+}
+
+func Test_Validate_Content_Type_Charset(t *testing.T) {
+	request := httptest.NewRequest("POST", "/any", http.NoBody)
+	request.Header.Set("Content-Type", "text/csv; charset=iso-8859-1")
+
+	err := validateContentType(nil, "text/csv")(httptest.NewRecorder(), request)
+
+	expected := "Invalid `Content-Type` header. Unsupported charset \"iso-8859-1\", expected `utf-8`"
+	if err == nil || err.Error() != expected || classify(err).kind != kindUnsupportedMediaType {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", expected, err)
+	}
+}
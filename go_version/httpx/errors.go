@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/csvx"
+	"bill-splitter/logx"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+// errorKind is the category of an error, deciding the response status
+type errorKind int
+
+const (
+	kindInternal errorKind = iota
+	kindBadRequest
+	kindUnsupportedMediaType
+	kindValidation
+	kindNotFound
+	kindConflict
+	kindPayloadTooLarge
+	kindNotAcceptable
+	kindPreconditionFailed
+)
+
+var kindStatus = map[errorKind]int{
+	kindInternal:             http.StatusInternalServerError,
+	kindBadRequest:           http.StatusBadRequest,
+	kindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	kindValidation:           http.StatusUnprocessableEntity,
+	kindNotFound:             http.StatusNotFound,
+	kindConflict:             http.StatusConflict,
+	kindPayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	kindNotAcceptable:        http.StatusNotAcceptable,
+	kindPreconditionFailed:   http.StatusPreconditionFailed,
+}
+
+// httpError is an error with the kind and stable code sent to clients
+type httpError struct {
+	kind errorKind
+	code string
+	err  error
+}
+
+func (he *httpError) Error() string {
+	return he.err.Error()
+}
+
+func (he *httpError) Unwrap() error {
+	return he.err
+}
+
+func newHttpError(kind errorKind, code string, err error) *httpError {
+	return &httpError{kind: kind, code: code, err: err}
+}
+
+// domainErrors maps accounting errors into their kind and code
+var domainErrors = []struct {
+	target error
+	kind   errorKind
+	code   string
+}{
+	// checked first, as CSV errors also wrap their cause, e.g. an invalid amount, with the line it is found
+	{csvx.ErrInvalidCSV, kindBadRequest, "invalid_csv"},
+	{accounting.ErrValidation, kindValidation, "validation_failed"},
+	{accounting.ErrInvalidSplit, kindValidation, "invalid_split"},
+	{accounting.ErrInvalidReceipt, kindValidation, "invalid_receipt"},
+	{accounting.ErrInvalidGroup, kindValidation, "invalid_group"},
+	{accounting.ErrUnknownMember, kindValidation, "unknown_member"},
+	{accounting.ErrInvalidSettlement, kindValidation, "invalid_settlement"},
+	{accounting.ErrInvalidAmount, kindValidation, "invalid_amount"},
+	{accounting.ErrAmountTooLarge, kindValidation, "invalid_amount"},
+	{accounting.ErrMixedCurrencies, kindBadRequest, "mixed_currencies"},
+	{accounting.ErrRateNotFound, kindBadRequest, "rate_not_found"},
+	{accounting.ErrInvalidOption, kindBadRequest, "invalid_option"},
+	{accounting.ErrGroupNotFound, kindNotFound, "group_not_found"},
+	{accounting.ErrTransactionNotFound, kindNotFound, "transaction_not_found"},
+	{accounting.ErrVersionConflict, kindPreconditionFailed, "version_conflict"},
+	{accounting.ErrInfeasibleSettlement, kindConflict, "infeasible_settlement"},
+}
+
+// classify finds the kind and code of any error, unknown errors are internal
+func classify(err error) *httpError {
+	var he *httpError
+	if errors.As(err, &he) {
+		return he
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return newHttpError(kindPayloadTooLarge, "payload_too_large", fmt.Errorf("request body is larger than %d bytes", maxBytesErr.Limit))
+	}
+	for _, de := range domainErrors {
+		if errors.Is(err, de.target) {
+			return newHttpError(de.kind, de.code, err)
+		}
+	}
+	return newHttpError(kindInternal, "internal_error", err)
+}
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     string                  `json:"code"`
+	Errors   []accounting.FieldError `json:"errors,omitempty"`
+	// RequestID correlates the error with the server logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// writeProblem writes the error as an application/problem+json body
+// internal errors details are only logged, not to leak them to clients
+func writeProblem(writer http.ResponseWriter, request *http.Request, err error) {
+	he := classify(err)
+	status := kindStatus[he.kind]
+
+	problem := Problem{
+		Type:      "urn:bill-splitter:problem:" + he.code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    he.Error(),
+		Instance:  request.URL.Path,
+		Code:      he.code,
+		RequestID: logx.RequestID(request.Context()),
+	}
+
+	var validationErr *accounting.ValidationError
+	if errors.As(err, &validationErr) {
+		problem.Errors = validationErr.Errors
+	}
+	if he.kind == kindInternal {
+		slog.ErrorContext(request.Context(), "request failed", "method", request.Method, "path", request.URL.Path, "error", err)
+		problem.Detail = "the server failed to process the request"
+	}
+
+	writer.Header().Set("Content-Type", problemContentType)
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(problem); err != nil {
+		slog.WarnContext(request.Context(), "failed to write response body", "error", err)
+	}
+}
@@ -0,0 +1,252 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/csvx"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// registerGroups register in the http.ServerMux all group resource endpoints
+func registerGroups(mux *http.ServeMux, groupService GroupService) {
+	mux.HandleFunc("POST /groups", mainHandlerFunc(validateContentType(createGroup(groupService))))
+	mux.HandleFunc("GET /groups", mainHandlerFunc(listGroups(groupService)))
+	mux.HandleFunc("GET /groups/{id}", mainHandlerFunc(getGroup(groupService)))
+
+	mux.HandleFunc(
+		"POST /groups/{id}/transactions",
+		mainHandlerFunc(validateContentType(addGroupTransactions(groupService), csvx.MediaType)),
+	)
+	mux.HandleFunc("GET /groups/{id}/transactions", mainHandlerFunc(listGroupTransactions(groupService)))
+	mux.HandleFunc("DELETE /groups/{id}/transactions", mainHandlerFunc(clearGroupTransactions(groupService)))
+	mux.HandleFunc(
+		"PATCH /groups/{id}/transactions/{txid}",
+		mainHandlerFunc(validateContentType(updateGroupTransaction(groupService))),
+	)
+	mux.HandleFunc("DELETE /groups/{id}/transactions/{txid}", mainHandlerFunc(deleteGroupTransaction(groupService)))
+	mux.HandleFunc("GET /groups/{id}/transactions/{txid}/history", mainHandlerFunc(groupTransactionHistory(groupService)))
+
+	mux.HandleFunc("GET /groups/{id}/balances", mainHandlerFunc(groupBalances(groupService)))
+	mux.HandleFunc("GET /groups/{id}/statement", mainHandlerFunc(groupStatement(groupService)))
+
+	mux.HandleFunc("POST /groups/{id}/settlements", mainHandlerFunc(validateContentType(recordSettlement(groupService))))
+	mux.HandleFunc("GET /groups/{id}/settlements", mainHandlerFunc(listSettlements(groupService)))
+}
+
+// createGroup accepts a JSON representation of a group with its members
+// and returns the created group with its id
+func createGroup(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var g accounting.Group
+		if err := readBody(request, &g); err != nil {
+			return err
+		}
+
+		created, err := service.CreateGroup(request.Context(), g)
+		if err != nil {
+			return err
+		}
+		writer.Header().Set("Location", "/groups/"+created.ID)
+		return writeBody(writer, request, http.StatusCreated, created)
+	}
+}
+
+func listGroups(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		groups, err := service.Groups(request.Context())
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, groups)
+	}
+}
+
+func getGroup(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		group, err := service.Group(request.Context(), request.PathValue("id"))
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, group)
+	}
+}
+
+// addGroupTransactions accepts a JSON or CSV representation of a transactions array
+// and appends them to the group ledger
+func addGroupTransactions(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var t accounting.Transactions
+		if err := readBody(request, &t); err != nil {
+			return err
+		}
+
+		if err := service.AddTransactions(actorContext(request), request.PathValue("id"), t...); err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusCreated, t)
+	}
+}
+
+func listGroupTransactions(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		transactions, err := service.GroupTransactions(request.Context(), request.PathValue("id"))
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, transactions)
+	}
+}
+
+// updateGroupTransaction accepts a JSON representation of the transaction fields to correct
+// and returns the new version, an `If-Match` header with the current version rejects concurrent changes
+func updateGroupTransaction(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var patch accounting.TransactionPatch
+		if err := readBody(request, &patch); err != nil {
+			return err
+		}
+
+		version, err := ifMatchVersion(request)
+		if err != nil {
+			return err
+		}
+
+		updated, err := service.UpdateTransaction(
+			actorContext(request), request.PathValue("id"), request.PathValue("txid"), version, patch,
+		)
+		if err != nil {
+			return err
+		}
+		writer.Header().Set("ETag", strconv.Quote(strconv.Itoa(updated.Version)))
+		return writeBody(writer, request, http.StatusOK, updated)
+	}
+}
+
+// deleteGroupTransaction removes a transaction from the balances, its history is kept
+func deleteGroupTransaction(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		version, err := ifMatchVersion(request)
+		if err != nil {
+			return err
+		}
+
+		if err := service.DeleteTransaction(actorContext(request), request.PathValue("id"), request.PathValue("txid"), version); err != nil {
+			return err
+		}
+		writer.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+func groupTransactionHistory(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		history, err := service.TransactionHistory(request.Context(), request.PathValue("id"), request.PathValue("txid"))
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, history)
+	}
+}
+
+func clearGroupTransactions(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		if err := service.ClearTransactions(actorContext(request), request.PathValue("id")); err != nil {
+			return err
+		}
+		writer.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+func groupBalances(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
+
+		balances, err := service.GroupBalances(request.Context(), request.PathValue("id"), opts...)
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, balances)
+	}
+}
+
+func groupStatement(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
+
+		statement, err := service.GroupStatement(request.Context(), request.PathValue("id"), opts...)
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, statement)
+	}
+}
+
+// recordSettlement accepts a JSON representation of a payment of an outstanding statement transaction
+// without amount it pays the whole outstanding amount, the query options must match the ones of the statement
+func recordSettlement(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var st accounting.Settlement
+		if err := readBody(request, &st); err != nil {
+			return err
+		}
+
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
+
+		recorded, err := service.RecordSettlement(request.Context(), request.PathValue("id"), st, opts...)
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusCreated, recorded)
+	}
+}
+
+func listSettlements(service GroupService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		settlements, err := service.GroupSettlements(request.Context(), request.PathValue("id"))
+		if err != nil {
+			return err
+		}
+		return writeBody(writer, request, http.StatusOK, settlements)
+	}
+}
+
+// maxActorLength bounds the actor recorded in the transactions history
+const maxActorLength = 128
+
+// actorContext carries the `X-Actor` header, who is making the changes, into the request context
+// blank, too long or non printable values are ignored
+func actorContext(request *http.Request) context.Context {
+	actor := strings.TrimSpace(request.Header.Get("X-Actor"))
+	if actor == "" || len(actor) > maxActorLength || strings.ContainsFunc(actor, func(r rune) bool { return !unicode.IsPrint(r) }) {
+		return request.Context()
+	}
+	return accounting.WithActor(request.Context(), actor)
+}
+
+// ifMatchVersion reads the transaction version of the `If-Match` header, quoted like an ETag or not
+// without header the version is 0, meaning any
+func ifMatchVersion(request *http.Request) (int, error) {
+	value := strings.TrimSpace(request.Header.Get("If-Match"))
+	if value == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(value, "W/"), `"`))
+	if err != nil || version < 1 {
+		return 0, newHttpError(kindBadRequest, "invalid_header", fmt.Errorf("If-Match must be a transaction version, got %q", value))
+	}
+	return version, nil
+}
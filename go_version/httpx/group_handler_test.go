@@ -0,0 +1,386 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var generatedID = regexp.MustCompile(`"id":"[0-9a-f]+"`)
+
+func Test_Group_Resources(t *testing.T) {
+	service := accounting.NewService()
+	mux := &http.ServeMux{}
+	registerGroups(mux, service)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	created := do("POST", "/groups", `{"name":"Bangkok","members":[{"name":"A"},{"name":"B"},{"name":"C"}]}`)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("\nExpected:	%+v\nGot:		%+v (%s)", http.StatusCreated, created.Code, created.Body)
+	}
+	var group accounting.Group
+	if err := json.Unmarshal(created.Body.Bytes(), &group); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	groupUrl := "/groups/" + group.ID
+
+	scenarios := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "should add transactions",
+			method:       "POST",
+			target:       groupUrl + "/transactions",
+			body:         `[{ "from": "A", "to": "B", "amount": 40, "occurred_at": "2025-01-01T10:00:00Z" },{ "from": "B", "to": "C", "amount": 40, "occurred_at": "2025-01-02T00:00:00Z" },{ "from": "C", "to": "A", "amount": 10, "occurred_at": "2025-01-03T10:00:00+02:00" }]`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `[{"id":"*","version":1,"from":"A","to":"B","amount":40.00,"occurred_at":"2025-01-01T10:00:00Z"},{"id":"*","version":1,"from":"B","to":"C","amount":40.00,"occurred_at":"2025-01-02T00:00:00Z"},{"id":"*","version":1,"from":"C","to":"A","amount":10.00,"occurred_at":"2025-01-03T10:00:00+02:00"}]`,
+		},
+		{
+			name:         "should list transactions",
+			method:       "GET",
+			target:       groupUrl + "/transactions",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":"*","version":1,"from":"A","to":"B","amount":40.00,"occurred_at":"2025-01-01T10:00:00Z"},{"id":"*","version":1,"from":"B","to":"C","amount":40.00,"occurred_at":"2025-01-02T00:00:00Z"},{"id":"*","version":1,"from":"C","to":"A","amount":10.00,"occurred_at":"2025-01-03T10:00:00+02:00"}]`,
+		},
+		{
+			name:         "should return balances",
+			method:       "GET",
+			target:       groupUrl + "/balances",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":30.00},{"name":"B","amount":0.00},{"name":"C","amount":-30.00}]`,
+		},
+		{
+			name:         "should return statement",
+			method:       "GET",
+			target:       groupUrl + "/statement",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated_balances":[{"name":"C","amount":0.00},{"name":"B","amount":0.00},{"name":"A","amount":0.00}],"transactions":[{"from":"C","to":"A","amount":30.00}]}`,
+		},
+		{
+			name:         "should return balances as of a date",
+			method:       "GET",
+			target:       groupUrl + "/balances?as_of=2025-01-02",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":40.00},{"name":"B","amount":0.00},{"name":"C","amount":-40.00}]`,
+		},
+		{
+			name:         "should return statement as of a time",
+			method:       "GET",
+			target:       groupUrl + "/statement?as_of=2025-01-01T12:00:00Z",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated_balances":[{"name":"B","amount":0.00},{"name":"C","amount":0.00},{"name":"A","amount":0.00}],"transactions":[{"from":"B","to":"A","amount":40.00}]}`,
+		},
+		{
+			name:         "should reject invalid as of",
+			method:       "GET",
+			target:       groupUrl + "/balances?as_of=yesterday",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject transaction with unknown member",
+			method:       "POST",
+			target:       groupUrl + "/transactions",
+			body:         `[{ "from": "A", "to": "Z", "amount": 40 }]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should record partial settlement",
+			method:       "POST",
+			target:       groupUrl + "/settlements",
+			body:         `{ "from": "C", "to": "A", "amount": 20 }`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "should return balances with settlements",
+			method:       "GET",
+			target:       groupUrl + "/balances",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":10.00},{"name":"B","amount":0.00},{"name":"C","amount":-10.00}]`,
+		},
+		{
+			name:         "should return outstanding and settled transactions",
+			method:       "GET",
+			target:       groupUrl + "/statement",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated_balances":[{"name":"C","amount":0.00},{"name":"B","amount":0.00},{"name":"A","amount":0.00}],"transactions":[{"from":"C","to":"A","amount":10.00}],"settled":[{"from":"C","to":"A","amount":20.00}]}`,
+		},
+		{
+			name:         "should reject settlement above the outstanding amount",
+			method:       "POST",
+			target:       groupUrl + "/settlements",
+			body:         `{ "from": "C", "to": "A", "amount": 15 }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should reject settlement without debt",
+			method:       "POST",
+			target:       groupUrl + "/settlements",
+			body:         `{ "from": "A", "to": "C" }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should record full settlement",
+			method:       "POST",
+			target:       groupUrl + "/settlements",
+			body:         `{ "from": "C", "to": "A", "paid_at": "2025-01-02T00:00:00Z" }`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "should return settled statement",
+			method:       "GET",
+			target:       groupUrl + "/statement",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated_balances":[{"name":"A","amount":0.00},{"name":"B","amount":0.00},{"name":"C","amount":0.00}],"transactions":[],"settled":[{"from":"C","to":"A","amount":30.00}]}`,
+		},
+		{
+			name:         "should clear transactions",
+			method:       "DELETE",
+			target:       groupUrl + "/transactions",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "should return zero balances after clear",
+			method:       "GET",
+			target:       groupUrl + "/balances",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":0.00},{"name":"B","amount":0.00},{"name":"C","amount":0.00}]`,
+		},
+		{
+			name:         "should return not found for unknown group",
+			method:       "GET",
+			target:       "/groups/missing/balances",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "should reject group without name",
+			method:       "POST",
+			target:       "/groups",
+			body:         `{"members":[{"name":"A"}]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			recorder := do(s.method, s.target, s.body)
+
+			if s.expectedCode != recorder.Code {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, recorder.Code)
+			}
+			// generated ids are not compared
+			actualBody := generatedID.ReplaceAllString(strings.TrimSpace(recorder.Body.String()), `"id":"*"`)
+			if s.expectedBody != "" && s.expectedBody != actualBody {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedBody, actualBody)
+			}
+		})
+	}
+
+	listed := do("GET", "/groups", "")
+	var groups []accounting.Group
+	if err := json.Unmarshal(listed.Body.Bytes(), &groups); err != nil || len(groups) != 1 || groups[0].ID != group.ID {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", []accounting.Group{group}, listed.Body.String())
+	}
+}
+
+func Test_Group_Transaction_Revisions(t *testing.T) {
+	service := accounting.NewService()
+	mux := &http.ServeMux{}
+	registerGroups(mux, service)
+
+	do := func(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range header {
+			request.Header[k] = v
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	created := do("POST", "/groups", `{"name":"Bangkok","members":[{"name":"A"},{"name":"B"}]}`, nil)
+	var group accounting.Group
+	if err := json.Unmarshal(created.Body.Bytes(), &group); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	added := do("POST", "/groups/"+group.ID+"/transactions", `[{ "from": "A", "to": "B", "amount": 400 }]`, nil)
+	var transactions accounting.Transactions
+	if err := json.Unmarshal(added.Body.Bytes(), &transactions); err != nil || len(transactions) != 1 {
+		t.Fatalf("unexpected response: %s (%+v)", added.Body, err)
+	}
+	transactionUrl := "/groups/" + group.ID + "/transactions/" + transactions[0].ID
+
+	scenarios := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		header       http.Header
+		expectedCode int
+		expectedBody string
+		expectedETag string
+	}{
+		{
+			name:         "should correct transaction",
+			method:       "PATCH",
+			target:       transactionUrl,
+			body:         `{ "amount": 40 }`,
+			header:       http.Header{"If-Match": {`"1"`}, "X-Actor": {"B"}},
+			expectedCode: http.StatusOK,
+			expectedBody: `"version":2,"from":"A","to":"B","amount":40.00`,
+			expectedETag: `"2"`,
+		},
+		{
+			name:         "should reject stale version",
+			method:       "PATCH",
+			target:       transactionUrl,
+			body:         `{ "amount": 4 }`,
+			header:       http.Header{"If-Match": {"1"}},
+			expectedCode: http.StatusPreconditionFailed,
+			expectedBody: `"code":"version_conflict"`,
+		},
+		{
+			name:         "should reject invalid version",
+			method:       "DELETE",
+			target:       transactionUrl,
+			header:       http.Header{"If-Match": {"*"}},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"code":"invalid_header"`,
+		},
+		{
+			name:         "should reject invalid correction",
+			method:       "PATCH",
+			target:       transactionUrl,
+			body:         `{ "amount": -4 }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should return balances with correction",
+			method:       "GET",
+			target:       "/groups/" + group.ID + "/balances",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":40.00},{"name":"B","amount":-40.00}]`,
+		},
+		{
+			name:         "should delete transaction",
+			method:       "DELETE",
+			target:       transactionUrl,
+			header:       http.Header{"If-Match": {`"2"`}, "X-Actor": {"A"}},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "should not list deleted transaction",
+			method:       "GET",
+			target:       "/groups/" + group.ID + "/transactions",
+			expectedCode: http.StatusOK,
+			expectedBody: `[]`,
+		},
+		{
+			name:         "should return not found for deleted transaction",
+			method:       "DELETE",
+			target:       transactionUrl,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"code":"transaction_not_found"`,
+		},
+		{
+			name:         "should return history",
+			method:       "GET",
+			target:       transactionUrl + "/history",
+			expectedCode: http.StatusOK,
+			expectedBody: `"version":3,"from":"A","to":"B","amount":40.00,`,
+		},
+		{
+			name:         "should return not found for unknown transaction history",
+			method:       "GET",
+			target:       "/groups/" + group.ID + "/transactions/missing/history",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			recorder := do(s.method, s.target, s.body, s.header)
+
+			if s.expectedCode != recorder.Code {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v (%s)", s.expectedCode, recorder.Code, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), s.expectedBody) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedBody, recorder.Body.String())
+			}
+			if s.expectedETag != recorder.Header().Get("ETag") {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedETag, recorder.Header().Get("ETag"))
+			}
+		})
+	}
+
+	history := do("GET", transactionUrl+"/history", "", nil)
+	var revisions []accounting.Revision
+	if err := json.Unmarshal(history.Body.Bytes(), &revisions); err != nil || len(revisions) != 3 {
+		t.Fatalf("unexpected response: %s (%+v)", history.Body, err)
+	}
+	if revisions[0].Amount != accounting.MustParseMoney("400") || revisions[1].Actor != "B" || revisions[2].Action != accounting.ActionDeleted {
+		t.Errorf("\nExpected:	created with 400, updated by B, deleted\nGot:		%+v", revisions)
+	}
+}
+
+func Test_Group_Clear_Keeps_History(t *testing.T) {
+	service := accounting.NewService()
+	mux := &http.ServeMux{}
+	registerGroups(mux, service)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		request.Header.Set("X-Actor", "A")
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	var group accounting.Group
+	_ = json.Unmarshal(do("POST", "/groups", `{"name":"Bangkok","members":[{"name":"A"},{"name":"B"}]}`).Body.Bytes(), &group)
+	var transactions accounting.Transactions
+	_ = json.Unmarshal(do("POST", "/groups/"+group.ID+"/transactions", `[{ "from": "A", "to": "B", "amount": 40 }]`).Body.Bytes(), &transactions)
+	do("POST", "/groups/"+group.ID+"/settlements", `{ "from": "B", "to": "A", "amount": 10 }`)
+
+	if recorder := do("DELETE", "/groups/"+group.ID+"/transactions", ""); recorder.Code != http.StatusNoContent {
+		t.Fatalf("\nExpected:	%+v\nGot:		%+v (%s)", http.StatusNoContent, recorder.Code, recorder.Body)
+	}
+
+	var revisions []accounting.Revision
+	history := do("GET", "/groups/"+group.ID+"/transactions/"+transactions[0].ID+"/history", "")
+	if err := json.Unmarshal(history.Body.Bytes(), &revisions); err != nil || len(revisions) != 2 {
+		t.Fatalf("unexpected response: %s (%+v)", history.Body, err)
+	}
+	if revisions[1].Action != accounting.ActionDeleted || revisions[1].Actor != "A" || revisions[1].Version != 2 {
+		t.Errorf("\nExpected:	deleted by A at version 2\nGot:		%+v", revisions[1])
+	}
+
+	var settlements accounting.Settlements
+	listed := do("GET", "/groups/"+group.ID+"/settlements", "")
+	if err := json.Unmarshal(listed.Body.Bytes(), &settlements); err != nil || len(settlements) != 1 || settlements[0].VoidedAt.IsZero() {
+		t.Errorf("\nExpected:	one voided settlement\nGot:		%s (%+v)", listed.Body, err)
+	}
+}
@@ -2,111 +2,198 @@ package httpx
 
 import (
 	"bill-splitter/accounting"
-	"encoding/json"
+	"bill-splitter/csvx"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
-	"slices"
 )
 
-var invalidContentType = errors.New("Invalid `Content-Type` header. Expected `application/json`")
+var invalidContentType = newHttpError(
+	kindUnsupportedMediaType,
+	"unsupported_media_type",
+	errors.New("Invalid `Content-Type` header. Expected `application/json`"),
+)
 
 type customHandler func(http.ResponseWriter, *http.Request) error
 
 // register register in the http.ServerMux all endpoints
-func register(mux *http.ServeMux, balanceService BalanceService, transactionService TransactionService) {
+func register(
+	mux *http.ServeMux,
+	balanceService BalanceService,
+	transactionService TransactionService,
+	expenseService ExpenseService,
+	groupService GroupService,
+) {
 	mux.HandleFunc(
 		"POST /balance/calculate",
-		mainHandlerFunc(validateContentType(balanceCalculate(balanceService))),
+		mainHandlerFunc(validateContentType(balanceCalculate(balanceService), csvx.MediaType)),
 	)
 
 	mux.HandleFunc(
 		"POST /transaction/minimize",
-		mainHandlerFunc(validateContentType(minimizeTransaction(transactionService))),
+		mainHandlerFunc(validateContentType(minimizeTransaction(transactionService), csvx.MediaType)),
+	)
+
+	mux.HandleFunc(
+		"POST /expense/calculate",
+		mainHandlerFunc(validateContentType(expenseCalculate(expenseService))),
+	)
+
+	mux.HandleFunc(
+		"POST /receipt/calculate",
+		mainHandlerFunc(validateContentType(receiptCalculate(expenseService))),
 	)
 
-	mux.HandleFunc("/", http.NotFound)
+	registerGroups(mux, groupService)
+
+	mux.HandleFunc("GET /openapi.json", mainHandlerFunc(openAPI()))
+
+	mux.HandleFunc("/", mainHandlerFunc(notFound))
 }
 
-// mainHandlerFunc generic handler for ServerMux
+// mainHandlerFunc generic handler for ServerMux, errors are written as problem details
 func mainHandlerFunc(innerHandler customHandler) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		if err := innerHandler(writer, request); err != nil {
-			switch {
-			case errors.Is(err, invalidContentType):
-				http.Error(writer, err.Error(), http.StatusBadRequest)
-			default:
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
-			}
+			writeProblem(writer, request, err)
 		}
 	}
 }
 
-// validateContentType handler to just validate if the request has the correct content type
-func validateContentType(next customHandler) customHandler {
-	return func(writer http.ResponseWriter, request *http.Request) error {
-		if !slices.Contains(request.Header.Values("Content-Type"), "application/json") {
-			return invalidContentType
-		}
+// notFound handler for any unknown route
+func notFound(_ http.ResponseWriter, request *http.Request) error {
+	return newHttpError(kindNotFound, "route_not_found", fmt.Errorf("no route for %s %s", request.Method, request.URL.Path))
+}
 
-		if next != nil {
-			return next(writer, request)
+// queryOptions maps the request query parameters into operation options
+// `currency` sets the settlement currency all amounts are converted to
+// `algorithm` selects how transactions are minimized (auto, greedy or exact)
+// `objective` selects what the settlement optimizes for (count, volume or payers)
+// `forbidden=A:B`, `preferred=A` and `max_transfer=A:100` set settlement constraints, all of them can repeat
+// `as_of`, an RFC 3339 time or a date, only takes into account transactions that occurred up to it
+func queryOptions(request *http.Request) ([]accounting.Option, error) {
+	var opts []accounting.Option
+	query := request.URL.Query()
+	if c := query.Get("currency"); c != "" {
+		opts = append(opts, accounting.WithSettlementCurrency(c))
+	}
+	if a := query.Get("algorithm"); a != "" {
+		opts = append(opts, accounting.WithAlgorithm(accounting.Algorithm(a)))
+	}
+	if o := query.Get("objective"); o != "" {
+		opts = append(opts, accounting.WithObjective(accounting.Objective(o)))
+	}
+
+	if a := query.Get("as_of"); a != "" {
+		asOf, err := accounting.ParseTime(a)
+		if err != nil {
+			return nil, fmt.Errorf("%w: as_of: %w", accounting.ErrInvalidOption, err)
 		}
-		return nil
+		opts = append(opts, accounting.WithAsOf(asOf))
+	}
+
+	constraints, err := accounting.ParseConstraints(query["forbidden"], query["preferred"], query["max_transfer"])
+	if err != nil {
+		return nil, err
 	}
+	if !constraints.IsZero() {
+		opts = append(opts, accounting.WithConstraints(constraints))
+	}
+	return opts, nil
 }
 
 // balanceCalculate entry point for calculate balance
-// accepts a JSON representation of a transactions array
-// and returns an array of balances
+// accepts a JSON or CSV representation of a transactions array
+// and returns an array of balances, as CSV when asked for in the `Accept` header
 func balanceCalculate(service BalanceService) customHandler {
 	return func(writer http.ResponseWriter, request *http.Request) error {
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				log.Println("failed to close request body: ", err)
-			}
-		}(request.Body)
-
 		var t accounting.Transactions
-		if err := json.NewDecoder(request.Body).Decode(&t); err != nil {
-			return fmt.Errorf("failed to read request body: %+v", err)
+		if err := readBody(request, &t); err != nil {
+			return err
 		}
 
-		balances := service.Calculate(t)
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
 
-		if err := json.NewEncoder(writer).Encode(balances); err != nil {
-			return fmt.Errorf("failed to write response body: %+v", err)
+		balances, err := service.Calculate(request.Context(), t, opts...)
+		if err != nil {
+			return err
 		}
 
-		writer.Header().Set("Content-Type", "application/json")
-		return nil
+		return writeBody(writer, request, http.StatusOK, balances)
 	}
 }
 
+// minimizeTransaction entry point for minimize transactions
+// accepts a JSON or CSV representation of a balances array
+// and returns a statement with updated balances and the transactions to settle them, only the transactions when CSV
 func minimizeTransaction(service TransactionService) customHandler {
 	return func(writer http.ResponseWriter, request *http.Request) error {
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
-			if err != nil {
-				log.Println("failed to close request body: ", err)
-			}
-		}(request.Body)
-
 		var b accounting.Balances
-		if err := json.NewDecoder(request.Body).Decode(&b); err != nil {
-			return fmt.Errorf("failed to read request body: %+v", err)
+		if err := readBody(request, &b); err != nil {
+			return err
 		}
 
-		statement := service.Minimize(b)
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
+
+		statement, err := service.Minimize(request.Context(), b, opts...)
+		if err != nil {
+			return err
+		}
+
+		return writeBody(writer, request, http.StatusOK, statement)
+	}
+}
+
+// expenseCalculate entry point for calculate balance from expenses
+// accepts a JSON representation of an expenses array, each one split between its participants
+// and returns an array of balances
+func expenseCalculate(service ExpenseService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var e accounting.Expenses
+		if err := readBody(request, &e); err != nil {
+			return err
+		}
+
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
+
+		balances, err := service.CalculateExpenses(request.Context(), e, opts...)
+		if err != nil {
+			return err
+		}
+
+		return writeBody(writer, request, http.StatusOK, balances)
+	}
+}
+
+// receiptCalculate entry point for calculate balance from an itemized receipt
+// accepts a JSON representation of a receipt, with items assigned to the people who consumed them
+// and returns each person share and the resulting balances
+func receiptCalculate(service ExpenseService) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		var r accounting.Receipt
+		if err := readBody(request, &r); err != nil {
+			return err
+		}
+
+		opts, err := queryOptions(request)
+		if err != nil {
+			return err
+		}
 
-		if err := json.NewEncoder(writer).Encode(statement); err != nil {
-			return fmt.Errorf("failed to write response body: %+v", err)
+		statement, err := service.CalculateReceipt(request.Context(), r, opts...)
+		if err != nil {
+			return err
 		}
 
-		writer.Header().Set("Content-Type", "application/json")
-		return nil
+		return writeBody(writer, request, http.StatusOK, statement)
 	}
 }
@@ -3,18 +3,23 @@ package httpx
 import (
 	"bill-splitter/accounting"
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
 func Test_Main_Handler_Func(t *testing.T) {
 	scenarios := []struct {
-		name          string
-		customHandler customHandler
-		expectedCode  int
+		name            string
+		customHandler   customHandler
+		expectedCode    int
+		expectedProblem *Problem
 	}{
 		{
 			name: "when no error",
@@ -29,13 +34,67 @@ func Test_Main_Handler_Func(t *testing.T) {
 				return errors.New("internal server error")
 			},
 			expectedCode: http.StatusInternalServerError,
+			expectedProblem: &Problem{
+				Type:     "urn:bill-splitter:problem:internal_error",
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Detail:   "the server failed to process the request",
+				Instance: "/any",
+				Code:     "internal_error",
+			},
 		},
 		{
-			name: "when bad request",
+			name: "when malformed body",
 			customHandler: func(writer http.ResponseWriter, request *http.Request) error {
-				return invalidContentType
+				var transactions accounting.Transactions
+				return readBody(request, &transactions)
 			},
 			expectedCode: http.StatusBadRequest,
+			expectedProblem: &Problem{
+				Type:     "urn:bill-splitter:problem:malformed_body",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "failed to read request body: EOF",
+				Instance: "/any",
+				Code:     "malformed_body",
+			},
+		},
+		{
+			name: "when unsupported media type",
+			customHandler: func(writer http.ResponseWriter, request *http.Request) error {
+				return invalidContentType
+			},
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "when validation error",
+			customHandler: func(writer http.ResponseWriter, request *http.Request) error {
+				return &accounting.ValidationError{Errors: []accounting.FieldError{{Index: 0, Field: "from", Message: "must not be empty"}}}
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedProblem: &Problem{
+				Type:     "urn:bill-splitter:problem:validation_failed",
+				Title:    "Unprocessable Entity",
+				Status:   http.StatusUnprocessableEntity,
+				Detail:   "validation failed: [0].from must not be empty",
+				Instance: "/any",
+				Code:     "validation_failed",
+				Errors:   []accounting.FieldError{{Index: 0, Field: "from", Message: "must not be empty"}},
+			},
+		},
+		{
+			name: "when not found",
+			customHandler: func(writer http.ResponseWriter, request *http.Request) error {
+				return fmt.Errorf("%w: abc", accounting.ErrGroupNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "when conflict",
+			customHandler: func(writer http.ResponseWriter, request *http.Request) error {
+				return fmt.Errorf("%w: no route", accounting.ErrInfeasibleSettlement)
+			},
+			expectedCode: http.StatusConflict,
 		},
 	}
 
@@ -50,6 +109,20 @@ func Test_Main_Handler_Func(t *testing.T) {
 			if s.expectedCode != recorder.Code {
 				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, recorder.Code)
 			}
+			if s.expectedCode == http.StatusOK {
+				return
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != problemContentType {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", problemContentType, contentType)
+			}
+
+			var actual Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+				t.Fatalf("unexpected error decoding problem: %+v", err)
+			}
+			if s.expectedProblem != nil && !reflect.DeepEqual(*s.expectedProblem, actual) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", *s.expectedProblem, actual)
+			}
 		})
 	}
 }
@@ -68,6 +141,21 @@ func Test_Validate_Content_Type(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name: "when content type has parameters",
+			modifyRequest: func(r *http.Request) *http.Request {
+				r.Header.Set("Content-Type", "Application/JSON; charset=UTF-8")
+				return r
+			},
+			expectedError: nil,
+		},
+		{
+			name: "when no content type",
+			modifyRequest: func(r *http.Request) *http.Request {
+				return r
+			},
+			expectedError: invalidContentType,
+		},
 		{
 			name: "when invalid content type",
 			modifyRequest: func(r *http.Request) *http.Request {
@@ -109,12 +197,12 @@ func Test_Balance_Calculate(t *testing.T) {
 		},
 	}
 
-	stubService := balanceServiceStub(func(_ accounting.Transactions) accounting.Balances {
+	stubService := balanceServiceStub(func(_ accounting.Transactions) (accounting.Balances, error) {
 		return accounting.Balances{
-			{"A", 30.0},
-			{"B", 0.0},
-			{"C", -30.0},
-		}
+			{Name: "A", Amount: accounting.MustParseMoney("30.0")},
+			{Name: "B", Amount: accounting.MustParseMoney("0.0")},
+			{Name: "C", Amount: accounting.MustParseMoney("-30.0")},
+		}, nil
 	})
 
 	for _, s := range scenarios {
@@ -149,17 +237,17 @@ func Test_Transactions_Minimize(t *testing.T) {
 		},
 	}
 
-	stubService := transactionServiceStub(func(_ accounting.Balances) accounting.Statement {
+	stubService := transactionServiceStub(func(_ accounting.Balances) (accounting.Statement, error) {
 		return accounting.Statement{
 			UpdatedBalances: accounting.Balances{
-				{"A", 0.0},
-				{"B", 0.0},
-				{"C", .0},
+				{Name: "A", Amount: accounting.MustParseMoney("0.0")},
+				{Name: "B", Amount: accounting.MustParseMoney("0.0")},
+				{Name: "C", Amount: accounting.MustParseMoney("0.0")},
 			},
 			Transactions: accounting.Transactions{
-				{From: "C", To: "A", Amount: 30.0},
+				{From: "C", To: "A", Amount: accounting.MustParseMoney("30.0")},
 			},
-		}
+		}, nil
 	})
 
 	for _, s := range scenarios {
@@ -176,14 +264,219 @@ func Test_Transactions_Minimize(t *testing.T) {
 	}
 }
 
-type balanceServiceStub func(a accounting.Transactions) accounting.Balances
+func Test_Expense_Calculate(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		bodyReader    io.Reader
+		expectedError error
+	}{
+		{
+			name:          "when valid json",
+			bodyReader:    bytes.NewBuffer([]byte(`[{ "paid_by": [{ "name": "A", "amount": 30 }], "total": 30, "participants": [{ "name": "A" },{ "name": "B" }] }]`)),
+			expectedError: nil,
+		},
+		{
+			name:          "when invalid json",
+			bodyReader:    bytes.NewBuffer([]byte(`{ "paid_by": [{ "name": "A", "amount": 30 }], "total": 30 }`)),
+			expectedError: errors.New("failed to read request body: json: cannot unmarshal object into Go value of type []accounting.Expense"),
+		},
+	}
+
+	stubService := expenseServiceStub(func(_ accounting.Expenses) (accounting.Balances, error) {
+		return accounting.Balances{
+			{Name: "A", Amount: accounting.MustParseMoney("15.0")},
+			{Name: "B", Amount: accounting.MustParseMoney("-15.0")},
+		}, nil
+	})
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := expenseCalculate(stubService)(
+				httptest.NewRecorder(),
+				httptest.NewRequest("POST", "/any", s.bodyReader),
+			)
+
+			if !errors.Is(err, s.expectedError) && s.expectedError.Error() != err.Error() {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+		})
+	}
+}
+
+func Test_Receipt_Calculate(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		bodyReader    io.Reader
+		expectedError error
+	}{
+		{
+			name:          "when valid json",
+			bodyReader:    bytes.NewBuffer([]byte(`{ "paid_by": [{ "name": "A", "amount": 33 }], "items": [{ "amount": 30, "consumers": ["A", "B"] }], "tip": 3 }`)),
+			expectedError: nil,
+		},
+		{
+			name:          "when invalid json",
+			bodyReader:    bytes.NewBuffer([]byte(`[{ "paid_by": [{ "name": "A", "amount": 30 }] }]`)),
+			expectedError: errors.New("failed to read request body: json: cannot unmarshal array into Go value of type accounting.Receipt"),
+		},
+		{
+			name:          "when invalid receipt",
+			bodyReader:    bytes.NewBuffer([]byte(`{ "paid_by": [{ "name": "A", "amount": 30 }], "items": [{ "amount": 30 }] }`)),
+			expectedError: accounting.ErrInvalidReceipt,
+		},
+	}
+
+	stubService := expenseServiceStub(func(_ accounting.Expenses) (accounting.Balances, error) {
+		return accounting.Balances{}, nil
+	})
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := receiptCalculate(stubService)(
+				httptest.NewRecorder(),
+				httptest.NewRequest("POST", "/any", s.bodyReader),
+			)
+
+			if !errors.Is(err, s.expectedError) && s.expectedError.Error() != err.Error() {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+		})
+	}
+}
+
+func Test_Calculation_Content_Type(t *testing.T) {
+	service := accounting.NewService()
+	scenarios := []struct {
+		name                string
+		handler             customHandler
+		body                string
+		accept              string
+		expectedContentType string
+	}{
+		{
+			name:                "when calculating balances",
+			handler:             balanceCalculate(service),
+			body:                `[{ "from": "A", "to": "B", "amount": 40 }]`,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "when calculating balances as csv",
+			handler:             balanceCalculate(service),
+			body:                `[{ "from": "A", "to": "B", "amount": 40 }]`,
+			accept:              "text/csv",
+			expectedContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:                "when minimizing transactions",
+			handler:             minimizeTransaction(service),
+			body:                `[{ "name": "A", "amount": 40 }, { "name": "B", "amount": -40 }]`,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "when calculating expenses",
+			handler:             expenseCalculate(service),
+			body:                `[{ "paid_by": [{ "name": "A", "amount": 30 }], "total": 30, "participants": [{ "name": "A" },{ "name": "B" }] }]`,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "when calculating a receipt",
+			handler:             receiptCalculate(service),
+			body:                `{ "paid_by": [{ "name": "A", "amount": 33 }], "items": [{ "amount": 30, "consumers": ["A", "B"] }], "tip": 3 }`,
+			expectedContentType: "application/json",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/any", bytes.NewBufferString(s.body))
+			request.Header.Set("Content-Type", "application/json")
+			if s.accept != "" {
+				request.Header.Set("Accept", s.accept)
+			}
+			recorder := httptest.NewRecorder()
 
-func (bss balanceServiceStub) Calculate(a accounting.Transactions) accounting.Balances {
+			if err := s.handler(recorder, request); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			// Result has the headers as they were when the body was written, a header set later is never sent
+			if contentType := recorder.Result().Header.Get("Content-Type"); contentType != s.expectedContentType {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedContentType, contentType)
+			}
+		})
+	}
+}
+
+func Test_Query_Options(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		query         string
+		expectedCount int
+		expectedError error
+	}{
+		{
+			name:          "when no options",
+			query:         "",
+			expectedCount: 0,
+		},
+		{
+			name:          "when currency and algorithm",
+			query:         "?currency=EUR&algorithm=exact",
+			expectedCount: 2,
+		},
+		{
+			name:          "when constraints",
+			query:         "?forbidden=A:B&forbidden=B:C&preferred=C&max_transfer=A:100",
+			expectedCount: 1,
+		},
+		{
+			name:          "when invalid forbidden pair",
+			query:         "?forbidden=A",
+			expectedError: accounting.ErrInvalidOption,
+		},
+		{
+			name:          "when invalid max transfer",
+			query:         "?max_transfer=A:abc",
+			expectedError: accounting.ErrInvalidOption,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			opts, err := queryOptions(httptest.NewRequest("POST", "/any"+s.query, http.NoBody))
+			if !errors.Is(err, s.expectedError) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+			}
+			if len(opts) != s.expectedCount {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCount, len(opts))
+			}
+		})
+	}
+}
+
+type balanceServiceStub func(a accounting.Transactions) (accounting.Balances, error)
+
+func (bss balanceServiceStub) Calculate(_ context.Context, a accounting.Transactions, _ ...accounting.Option) (accounting.Balances, error) {
 	return bss(a)
 }
 
-type transactionServiceStub func(b accounting.Balances) accounting.Statement
+type transactionServiceStub func(b accounting.Balances) (accounting.Statement, error)
 
-func (tss transactionServiceStub) Minimize(b accounting.Balances) accounting.Statement {
+func (tss transactionServiceStub) Minimize(_ context.Context, b accounting.Balances, _ ...accounting.Option) (accounting.Statement, error) {
 	return tss(b)
 }
+
+type expenseServiceStub func(e accounting.Expenses) (accounting.Balances, error)
+
+func (ess expenseServiceStub) CalculateExpenses(_ context.Context, e accounting.Expenses, _ ...accounting.Option) (accounting.Balances, error) {
+	return ess(e)
+}
+
+func (ess expenseServiceStub) CalculateReceipt(_ context.Context, r accounting.Receipt, _ ...accounting.Option) (accounting.ReceiptStatement, error) {
+	expense, shares, err := r.Expense()
+	if err != nil {
+		return accounting.ReceiptStatement{}, err
+	}
+	balances, err := ess(accounting.Expenses{expense})
+	return accounting.ReceiptStatement{Shares: shares, Balances: balances}, err
+}
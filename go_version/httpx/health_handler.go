@@ -0,0 +1,95 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// readinessTimeout bounds every readiness check, probes usually time out after a few seconds
+const readinessTimeout = 2 * time.Second
+
+// readinessCheck is a named dependency that must be healthy to serve requests
+type readinessCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+// Readiness is the body of /readyz, checks maps every check name to "ok" or its error
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// BuildInfo is the body of /version
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// readBuildInfo is replaced by tests, binaries built by tests carry no version control information
+var readBuildInfo = debug.ReadBuildInfo
+
+// registerHealth register in the http.ServerMux the probes used by orchestrators
+func registerHealth(mux *http.ServeMux, hs *HttpServer, checks []readinessCheck) {
+	mux.HandleFunc("GET /healthz", mainHandlerFunc(healthz()))
+	mux.HandleFunc("GET /readyz", mainHandlerFunc(readyz(hs, checks)))
+	mux.HandleFunc("GET /version", mainHandlerFunc(version()))
+}
+
+// healthz the process is alive as long as it answers
+func healthz() customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		return writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// readyz the server is ready when it is listening, not shutting down, and every check passes
+func readyz(hs *HttpServer, checks []readinessCheck) customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		if !hs.ready.Load() {
+			return writeJSON(writer, http.StatusServiceUnavailable, Readiness{Status: "not ready"})
+		}
+
+		ctx, cancel := context.WithTimeout(request.Context(), readinessTimeout)
+		defer cancel()
+
+		readiness := Readiness{Status: "ready", Checks: map[string]string{}}
+		status := http.StatusOK
+		for _, c := range checks {
+			readiness.Checks[c.name] = "ok"
+			if err := c.check(ctx); err != nil {
+				readiness.Checks[c.name] = err.Error()
+				readiness.Status = "not ready"
+				status = http.StatusServiceUnavailable
+			}
+		}
+		return writeJSON(writer, status, readiness)
+	}
+}
+
+// version reports the module version and the version control revision it was built from
+func version() customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		info := BuildInfo{Version: "unknown"}
+		if bi, ok := readBuildInfo(); ok {
+			info.Version = bi.Main.Version
+			info.GoVersion = bi.GoVersion
+			for _, setting := range bi.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					info.Revision = setting.Value
+				case "vcs.time":
+					info.Time = setting.Value
+				case "vcs.modified":
+					info.Modified = setting.Value == "true"
+				}
+			}
+		}
+		return writeJSON(writer, http.StatusOK, info)
+	}
+}
@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/config"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func Test_Health_Resources(t *testing.T) {
+	storageErr := error(nil)
+	accService := accounting.NewService()
+	hs := NewServer(
+		config.Default().Server,
+		accService, accService, accService, accService,
+		WithReadinessCheck("storage", func(ctx context.Context) error { return storageErr }),
+	)
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.24.3",
+			Main:      debug.Module{Path: "bill-splitter", Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.time", Value: "2026-01-01T00:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+	defer func() { readBuildInfo = debug.ReadBuildInfo }()
+
+	scenarios := []struct {
+		name         string
+		prepare      func()
+		target       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "should be alive",
+			target:       "/healthz",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name:         "should not be ready before running",
+			target:       "/readyz",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"not ready"}`,
+		},
+		{
+			name:         "should be ready while running",
+			prepare:      func() { hs.ready.Store(true) },
+			target:       "/readyz",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ready","checks":{"storage":"ok"}}`,
+		},
+		{
+			name:         "should not be ready when storage fails",
+			prepare:      func() { storageErr = errors.New("disk is gone") },
+			target:       "/readyz",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"not ready","checks":{"storage":"disk is gone"}}`,
+		},
+		{
+			name: "should not be ready once closing",
+			prepare: func() {
+				storageErr = nil
+				hs.Close()
+			},
+			target:       "/readyz",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"not ready"}`,
+		},
+		{
+			name:         "should report build info",
+			target:       "/version",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"version":"v1.2.3","revision":"abc123","time":"2026-01-01T00:00:00Z","modified":true,"go_version":"go1.24.3"}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if s.prepare != nil {
+				s.prepare()
+			}
+			recorder := httptest.NewRecorder()
+
+			hs.server.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", s.target, nil))
+
+			if s.expectedCode != recorder.Code {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, recorder.Code)
+			}
+			if s.expectedBody != strings.TrimSpace(recorder.Body.String()) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package httpx
+
+import (
+	"bill-splitter/logx"
+	"bill-splitter/metrics"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusWriter remembers the status code written to the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+func (sw *statusWriter) statusCode() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
+
+// RequestIDHeader is the header carrying the request id, it is accepted from clients and echoed on responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength avoids clients flooding logs through the request id
+const maxRequestIDLength = 128
+
+// requestID keeps a valid client request id or generates a new one, carrying it in the request context
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		id := request.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = logx.NewRequestID()
+		}
+		writer.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(writer, request.WithContext(logx.WithRequestID(request.Context(), id)))
+	})
+}
+
+// validRequestID accepts only printable ascii without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// observe logs every request served by the mux and records its metrics, when enabled, labeled by the pattern it
+// matched
+func observe(httpMetrics *metrics.HTTPMetrics, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: writer}
+		mux.ServeHTTP(sw, request)
+		duration := time.Since(start)
+
+		if httpMetrics != nil {
+			httpMetrics.ObserveRequest(methodLabel(request.Method), route(request), sw.statusCode(), duration)
+		}
+		slog.InfoContext(request.Context(), "request served",
+			"method", request.Method,
+			"path", request.URL.Path,
+			"route", route(request),
+			"status", sw.statusCode(),
+			"duration", duration,
+			"remote_addr", request.RemoteAddr,
+		)
+	})
+}
+
+// methodLabel keeps the standard methods as metric labels, any other is `other` so clients can not create new series
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// route is the path of the pattern matched by the mux, which sets it on the request while routing
+func route(request *http.Request) string {
+	if request.Pattern == "" {
+		return "unmatched"
+	}
+	_, path, found := strings.Cut(request.Pattern, " ")
+	if !found {
+		return request.Pattern
+	}
+	return path
+}
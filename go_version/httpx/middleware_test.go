@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bill-splitter/config"
+	"bill-splitter/logx"
+	"bill-splitter/metrics"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Observe(t *testing.T) {
+	registry := metrics.NewRegistry()
+	accService := accounting.NewService(accounting.WithRecorder(metrics.NewServiceMetrics(registry)))
+	s := NewServer(config.Default().Server, accService, accService, accService, accService, WithMetrics(registry))
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	do("POST", "/transaction/minimize", `[{"name":"A","amount":10},{"name":"B","amount":-10}]`)
+	do("POST", "/transaction/minimize", `[`)
+	do("GET", "/groups/abc", "")
+	do("GET", "/unknown/path", "")
+	do("RANDOM", "/unknown/path", "")
+	scrape := do("GET", "/metrics", "")
+
+	if contentType := scrape.Header().Get("Content-Type"); contentType != metrics.ContentType {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", metrics.ContentType, contentType)
+	}
+
+	expectedLines := []string{
+		`bill_splitter_http_requests_total{method="POST",route="/transaction/minimize",status="200"} 1`,
+		`bill_splitter_http_requests_total{method="POST",route="/transaction/minimize",status="400"} 1`,
+		`bill_splitter_http_requests_total{method="GET",route="/groups/{id}",status="404"} 1`,
+		`bill_splitter_http_requests_total{method="GET",route="/",status="404"} 1`,
+		`bill_splitter_http_requests_total{method="other",route="/",status="404"} 1`,
+		`bill_splitter_http_request_duration_seconds_count{method="POST",route="/transaction/minimize",status="200"} 1`,
+		`bill_splitter_minimize_transactions_count{objective="count"} 1`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(scrape.Body.String(), line+"\n") {
+			t.Errorf("\nExpected:	%+v\nGot:		%+v", line, scrape.Body.String())
+		}
+	}
+}
+
+func Test_Request_ID(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		header     string
+		expectedID func(id string) bool
+	}{
+		{
+			name:       "should keep client request id",
+			header:     "abc-123",
+			expectedID: func(id string) bool { return id == "abc-123" },
+		},
+		{
+			name:       "should generate request id when missing",
+			expectedID: func(id string) bool { return len(id) == 32 },
+		},
+		{
+			name:       "should replace invalid request id",
+			header:     "has spaces",
+			expectedID: func(id string) bool { return len(id) == 32 },
+		},
+		{
+			name:       "should replace too long request id",
+			header:     strings.Repeat("a", 129),
+			expectedID: func(id string) bool { return len(id) == 32 },
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var contextID string
+			handler := requestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				contextID = logx.RequestID(request.Context())
+			}))
+			request := httptest.NewRequest("GET", "/", nil)
+			if s.header != "" {
+				request.Header.Set(RequestIDHeader, s.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			responseID := recorder.Header().Get(RequestIDHeader)
+			if !s.expectedID(responseID) || responseID != contextID {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", contextID, responseID)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package httpx
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes the stateless endpoints, it is checked against the handlers by tests
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// openAPI serves the embedded OpenAPI document
+func openAPI() customHandler {
+	return func(writer http.ResponseWriter, request *http.Request) error {
+		writer.Header().Set("Content-Type", "application/json")
+		_, err := writer.Write(openAPISpec)
+		return err
+	}
+}
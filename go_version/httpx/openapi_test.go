@@ -0,0 +1,324 @@
+package httpx
+
+import (
+	"bill-splitter/accounting"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_OpenAPI_Responses(t *testing.T) {
+	spec := loadSpec(t)
+
+	rates, _ := accounting.NewStaticRates("EUR", map[string]string{"THB": "38.5"})
+	accService := accounting.NewService(accounting.WithRateProvider(rates))
+	mux := &http.ServeMux{}
+	register(mux, accService, accService, accService, accService)
+
+	scenarios := []struct {
+		name         string
+		path         string
+		query        string
+		contentType  string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "should calculate balances",
+			path:         "/balance/calculate",
+			body:         `[{"from":"A","to":"B","amount":40},{"from":"B","to":"C","amount":"40.5","currency":"EUR"}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should calculate balances in settlement currency",
+			path:         "/balance/calculate",
+			query:        "?currency=EUR",
+			body:         `[{"from":"A","to":"B","amount":770,"currency":"THB"}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should calculate balances with several payers",
+			path:         "/balance/calculate",
+			body:         `[{"to":"C","amount":90,"contributors":[{"name":"A","amount":60},{"name":"B","amount":30}]}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should reject contributors not summing to the amount",
+			path:         "/balance/calculate",
+			body:         `[{"to":"C","amount":90,"contributors":[{"name":"A","amount":60}]}]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should reject invalid transactions",
+			path:         "/balance/calculate",
+			body:         `[{"from":"","to":"B","amount":-1}]`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "should reject malformed transactions",
+			path:         "/balance/calculate",
+			body:         `{"from":"A"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject unsupported media type",
+			path:         "/balance/calculate",
+			contentType:  "text/plain",
+			body:         `[]`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "should minimize transactions",
+			path:         "/transaction/minimize",
+			body:         `[{"name":"A","amount":30},{"name":"B","amount":0},{"name":"C","amount":-30}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should minimize settled balances",
+			path:         "/transaction/minimize",
+			body:         `[{"name":"A","amount":0}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should minimize converted balances with rates",
+			path:         "/transaction/minimize",
+			query:        "?currency=EUR&objective=volume",
+			body:         `[{"name":"A","amount":770,"currency":"THB"},{"name":"B","amount":-20,"currency":"EUR"}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should minimize with constraints",
+			path:         "/transaction/minimize",
+			query:        "?forbidden=C:A&preferred=B&max_transfer=C:10",
+			body:         `[{"name":"A","amount":30},{"name":"B","amount":0},{"name":"C","amount":-30}]`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should reject infeasible constraints",
+			path:         "/transaction/minimize",
+			query:        "?forbidden=C:A&forbidden=C:B",
+			body:         `[{"name":"A","amount":30},{"name":"B","amount":0},{"name":"C","amount":-30}]`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should reject invalid options",
+			path:         "/transaction/minimize",
+			query:        "?algorithm=random",
+			body:         `[{"name":"A","amount":30},{"name":"C","amount":-30}]`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", s.path+s.query, bytes.NewBufferString(s.body))
+			contentType := "application/json"
+			if s.contentType != "" {
+				contentType = s.contentType
+			}
+			request.Header.Set("Content-Type", contentType)
+			recorder := httptest.NewRecorder()
+
+			mux.ServeHTTP(recorder, request)
+			// Result has the headers as they were when the body was written, like a real client sees them
+			response := recorder.Result()
+
+			if s.expectedCode != response.StatusCode {
+				t.Fatalf("\nExpected:	%+v\nGot:		%+v (%s)", s.expectedCode, response.StatusCode, recorder.Body.String())
+			}
+
+			schema, err := spec.responseSchema(s.path, "post", response.StatusCode, response.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("response not described by the spec: %+v", err)
+			}
+
+			decoder := json.NewDecoder(recorder.Body)
+			decoder.UseNumber()
+			var body any
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatalf("unexpected error decoding body: %+v", err)
+			}
+			if err := spec.validate(schema, body, "$"); err != nil {
+				t.Errorf("response does not match the spec: %+v\n%s", err, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func Test_OpenAPI_Served(t *testing.T) {
+	spec := loadSpec(t)
+	mux := &http.ServeMux{}
+	register(mux, balanceServiceStub(nil), transactionServiceStub(nil), expenseServiceStub(nil), nil)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	if !bytes.Equal(openAPISpec, recorder.Body.Bytes()) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", string(openAPISpec), recorder.Body.String())
+	}
+
+	// every documented operation must be routed to a handler
+	for path, item := range spec.Paths {
+		for method := range item {
+			request := httptest.NewRequest(strings.ToUpper(method), path, nil)
+			if _, pattern := mux.Handler(request); pattern == "/" {
+				t.Errorf("%s %s is documented but not routed", method, path)
+			}
+		}
+	}
+}
+
+// openAPIDocument is the part of the OpenAPI document needed to find and check response schemas
+type openAPIDocument struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Responses map[string]openAPIResponse `json:"responses"`
+		Schemas   map[string]map[string]any  `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema map[string]any `json:"schema"`
+	} `json:"content"`
+}
+
+func loadSpec(t *testing.T) openAPIDocument {
+	var spec openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %+v", err)
+	}
+	return spec
+}
+
+func (d openAPIDocument) responseSchema(path, method string, status int, contentType string) (map[string]any, error) {
+	operation, ok := d.Paths[path][method]
+	if !ok {
+		return nil, fmt.Errorf("no operation %s %s", method, path)
+	}
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		return nil, fmt.Errorf("no response %d", status)
+	}
+	if name, found := strings.CutPrefix(response.Ref, "#/components/responses/"); found {
+		response = d.Components.Responses[name]
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("no content %q for response %d", contentType, status)
+	}
+	return content.Schema, nil
+}
+
+// validate checks the value against the JSON Schema keywords used by the document:
+// $ref, type, properties, required, additionalProperties, items, enum, pattern, minLength and minimum
+func (d openAPIDocument) validate(schema map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name, _ := strings.CutPrefix(ref, "#/components/schemas/")
+		target, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown reference %s", path, ref)
+		}
+		return d.validate(target, value, path)
+	}
+
+	if types, ok := schema["type"]; ok {
+		var allowed []string
+		switch ts := types.(type) {
+		case string:
+			allowed = []string{ts}
+		case []any:
+			for _, t := range ts {
+				allowed = append(allowed, t.(string))
+			}
+		}
+		if actual := jsonType(value); !slices.Contains(allowed, actual) && !(actual == "integer" && slices.Contains(allowed, "number")) {
+			return fmt.Errorf("%s: expected %v, got %s", path, allowed, actual)
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := v[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required %s", path, name)
+				}
+			}
+		}
+		for name, property := range v {
+			propertySchema, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+				continue
+			}
+			if err := d.validate(propertySchema, property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := d.validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			return fmt.Errorf("%s: %q does not match %s", path, v, pattern)
+		}
+		if minLength, ok := schema["minLength"].(float64); ok && len(v) < int(minLength) {
+			return fmt.Errorf("%s: %q is shorter than %v", path, v, minLength)
+		}
+	case json.Number:
+		if minimum, ok := schema["minimum"].(float64); ok {
+			if f, _ := v.Float64(); f < minimum {
+				return fmt.Errorf("%s: %v is less than %v", path, v, minimum)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON Schema type of a value decoded with UseNumber
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -2,66 +2,170 @@ package httpx
 
 import (
 	"bill-splitter/accounting"
+	"bill-splitter/config"
+	"bill-splitter/metrics"
 	"context"
-	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 type BalanceService interface {
-	Calculate(accounting.Transactions) accounting.Balances
+	Calculate(context.Context, accounting.Transactions, ...accounting.Option) (accounting.Balances, error)
 }
 
 type TransactionService interface {
-	Minimize(accounting.Balances) accounting.Statement
+	Minimize(context.Context, accounting.Balances, ...accounting.Option) (accounting.Statement, error)
+}
+
+type ExpenseService interface {
+	CalculateExpenses(context.Context, accounting.Expenses, ...accounting.Option) (accounting.Balances, error)
+	CalculateReceipt(context.Context, accounting.Receipt, ...accounting.Option) (accounting.ReceiptStatement, error)
+}
+
+type GroupService interface {
+	CreateGroup(context.Context, accounting.Group) (accounting.Group, error)
+	Group(context.Context, string) (accounting.Group, error)
+	Groups(context.Context) ([]accounting.Group, error)
+	AddTransactions(context.Context, string, ...accounting.Transaction) error
+	GroupTransactions(context.Context, string) (accounting.Transactions, error)
+	UpdateTransaction(context.Context, string, string, int, accounting.TransactionPatch) (accounting.Transaction, error)
+	DeleteTransaction(context.Context, string, string, int) error
+	TransactionHistory(context.Context, string, string) ([]accounting.Revision, error)
+	ClearTransactions(context.Context, string) error
+	GroupBalances(context.Context, string, ...accounting.Option) (accounting.Balances, error)
+	GroupStatement(context.Context, string, ...accounting.Option) (accounting.Statement, error)
+	RecordSettlement(context.Context, string, accounting.Settlement, ...accounting.Option) (accounting.Settlement, error)
+	GroupSettlements(context.Context, string) (accounting.Settlements, error)
 }
 
 type HttpServer struct {
-	server    *http.Server
-	osSigChan chan os.Signal
+	server          *http.Server
+	shutdownTimeout time.Duration
+	osSigChan       chan os.Signal
+	// ready is true while the server is listening and not shutting down
+	ready atomic.Bool
+}
+
+// ServerOption configures optional features of the server
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	metrics *metrics.Registry
+	checks  []readinessCheck
+}
+
+// WithMetrics serves the registry at /metrics, adding the requests metrics to it
+func WithMetrics(registry *metrics.Registry) ServerOption {
+	return func(o *serverOptions) {
+		o.metrics = registry
+	}
+}
+
+// WithReadinessCheck makes /readyz fail while the check fails, e.g. when the storage is not reachable
+func WithReadinessCheck(name string, check func(context.Context) error) ServerOption {
+	return func(o *serverOptions) {
+		o.checks = append(o.checks, readinessCheck{name: name, check: check})
+	}
 }
 
 // NewServer set up application server
-func NewServer(balanceService BalanceService, transactionService TransactionService) *HttpServer {
+func NewServer(
+	cfg config.Server,
+	balanceService BalanceService,
+	transactionService TransactionService,
+	expenseService ExpenseService,
+	groupService GroupService,
+	opts ...ServerOption,
+) *HttpServer {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	serverMux := &http.ServeMux{}
-	register(serverMux, balanceService, transactionService)
+	register(serverMux, balanceService, transactionService, expenseService, groupService)
+
+	var httpMetrics *metrics.HTTPMetrics
+	if o.metrics != nil {
+		serverMux.Handle("GET /metrics", o.metrics.Handler())
+		httpMetrics = metrics.NewHTTPMetrics(o.metrics)
+	}
 
 	server := &http.Server{
-		Addr:    ":8000",
-		Handler: serverMux,
+		Addr:         cfg.Addr,
+		Handler:      requestID(limitBody(cfg.MaxBodyBytes, observe(httpMetrics, serverMux))),
+		ReadTimeout:  time.Duration(cfg.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.IdleTimeout),
 	}
 
-	hs := HttpServer{
-		server:    server,
-		osSigChan: make(chan os.Signal, 1),
+	hs := &HttpServer{
+		server:          server,
+		shutdownTimeout: time.Duration(cfg.ShutdownTimeout),
+		osSigChan:       make(chan os.Signal, 1),
 	}
-	return &hs
+	registerHealth(serverMux, hs, o.checks)
+	return hs
+}
+
+// limitBody caps the size of every request body, reading beyond it fails with http.MaxBytesError
+func limitBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		request.Body = http.MaxBytesReader(writer, request.Body, maxBytes)
+		next.ServeHTTP(writer, request)
+	})
 }
 
-// Run runs server
-func (hs *HttpServer) Run() {
+// Run serves requests until the process is interrupted or Close is called, then drains in flight requests for at
+// most the shutdown timeout. It only returns an error if the server could not start or stop cleanly
+func (hs *HttpServer) Run() error {
+	signal.Notify(hs.osSigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(hs.osSigChan)
+
+	listener, err := net.Listen("tcp", hs.server.Addr)
+	if err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	serveErr := make(chan error, 1)
 	go func() {
-		log.Println("server started at :8000")
-		if err := hs.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("server failed: %+v", err)
-			return
-		}
+		serveErr <- hs.server.Serve(listener)
 	}()
+	hs.ready.Store(true)
+	slog.Info("server started", "addr", listener.Addr().String())
 
-	signal.Notify(hs.osSigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
-	<-hs.osSigChan
+	select {
+	case err := <-serveErr:
+		hs.ready.Store(false)
+		return fmt.Errorf("server failed: %w", err)
+	case <-hs.osSigChan:
+	}
+	hs.ready.Store(false)
 
-	// should use a timed context
-	if err := hs.server.Shutdown(context.Background()); err != nil {
-		log.Fatalf("server failed to shutdown: %+v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), hs.shutdownTimeout)
+	defer cancel()
+	if err := hs.server.Shutdown(ctx); err != nil {
+		// requests still running after the deadline are cut
+		_ = hs.server.Close()
+		return fmt.Errorf("server failed to shutdown: %w", err)
 	}
-	log.Println("server stopped")
+	slog.Info("server stopped")
+	return nil
 }
 
+// Close asks a running server to shut down, it does not wait for Run to return
 func (hs *HttpServer) Close() {
-	log.Println("server shutting down...")
-	hs.osSigChan <- syscall.SIGTERM
+	slog.Info("server shutting down")
+	hs.ready.Store(false)
+	select {
+	case hs.osSigChan <- syscall.SIGTERM:
+	default:
+	}
 }
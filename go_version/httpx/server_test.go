@@ -2,11 +2,18 @@ package httpx
 
 import (
 	"bill-splitter/accounting"
+	"bill-splitter/config"
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_Server_Integration(t *testing.T) {
@@ -27,7 +34,7 @@ func Test_Server_Integration(t *testing.T) {
 				return r
 			},
 			expectedCode: http.StatusOK,
-			expectedBody: `[{"name":"A","amount":30},{"name":"B","amount":0},{"name":"C","amount":-30}]`,
+			expectedBody: `[{"name":"A","amount":30.00},{"name":"B","amount":0.00},{"name":"C","amount":-30.00}]`,
 		},
 		{
 			name: "should process transactions and return balances",
@@ -36,8 +43,96 @@ func Test_Server_Integration(t *testing.T) {
 				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
 				return r
 			},
+			expectedCode: http.StatusUnsupportedMediaType,
+			expectedBody: `{"type":"urn:bill-splitter:problem:unsupported_media_type","title":"Unsupported Media Type","status":415,"detail":"Invalid ` + "`Content-Type`" + ` header. Expected ` + "`application/json`" + ` or ` + "`text/csv`" + `","instance":"/balance/calculate","code":"unsupported_media_type","request_id":"test-request"}`,
+		},
+		{
+			name: "should process csv transactions and return csv balances",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBufferString("from,to,amount,description\nA,B,40,Taxi\nB,C,40,\nC,A,10,\n")
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"text/csv"}, "Accept": {"text/csv"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "name,amount,currency\nA,30.00,\nB,0.00,\nC,-30.00,",
+		},
+		{
+			name: "should return csv errors with their line",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBufferString("name,amount\nA,30\nB,thirty\n")
+				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize", b)
+				r.Header = map[string][]string{"Content-Type": {"text/csv"}}
+				return r
+			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "Invalid `Content-Type` header. Expected `application/json`",
+			expectedBody: `{"type":"urn:bill-splitter:problem:invalid_csv","title":"Bad Request","status":400,"detail":"line 3, amount: invalid amount: \"thirty\"","instance":"/transaction/minimize","code":"invalid_csv","request_id":"test-request"}`,
+		},
+		{
+			name: "should process balances and return csv transactions",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"name":"A","amount":30.0},{"name":"B","amount":0.0},{"name":"C","amount":-30.0}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}, "Accept": {"text/csv"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "from,to,amount,currency,date,description\nC,A,30.00,,,",
+		},
+		{
+			name: "should accept json with charset",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{ "from": "A", "to": "B", "amount": 40 }]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json; charset=utf-8"}, "Accept": {"application/json, */*;q=0.1"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":40.00},{"name":"B","amount":-40.00}]`,
+		},
+		{
+			name: "should return not acceptable",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{ "from": "A", "to": "B", "amount": 40 }]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}, "Accept": {"text/html"}}
+				return r
+			},
+			expectedCode: http.StatusNotAcceptable,
+			expectedBody: `{"type":"urn:bill-splitter:problem:not_acceptable","title":"Not Acceptable","status":406,"detail":"Invalid ` + "`Accept`" + ` header. No supported media type is acceptable","instance":"/balance/calculate","code":"not_acceptable","request_id":"test-request"}`,
+		},
+		{
+			name: "should calculate balances as of a date",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{ "from": "A", "to": "B", "amount": 40, "occurred_at": "2025-01-01T10:00:00Z" },{ "from": "B", "to": "A", "amount": 300, "description": "hotel", "occurred_at": "2025-01-03T10:00:00Z" }]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate?as_of=2025-01-02", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":40.00},{"name":"B","amount":-40.00}]`,
+		},
+		{
+			name: "should return validation errors",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{ "from": "A", "to": "B", "amount": -40 },{ "from": "", "to": "C", "amount": 10 }]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"type":"urn:bill-splitter:problem:validation_failed","title":"Unprocessable Entity","status":422,"detail":"validation failed: [0].amount must be positive, [1].from must not be empty","instance":"/balance/calculate","code":"validation_failed","errors":[{"index":0,"field":"amount","message":"must be positive"},{"index":1,"field":"from","message":"must not be empty"}],"request_id":"test-request"}`,
+		},
+		{
+			name: "should return validation errors for invalid currency",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{ "from": "A", "to": "B", "amount": 40, "currency": "XXXX" }]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/balance/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"type":"urn:bill-splitter:problem:validation_failed","title":"Unprocessable Entity","status":422,"detail":"validation failed: [0].currency must be a 3 letter code","instance":"/balance/calculate","code":"validation_failed","errors":[{"index":0,"field":"currency","message":"must be a 3 letter code"}],"request_id":"test-request"}`,
 		},
 		{
 			name: "should process balances and reduce transactions",
@@ -48,7 +143,62 @@ func Test_Server_Integration(t *testing.T) {
 				return r
 			},
 			expectedCode: http.StatusOK,
-			expectedBody: `{"updated_balances":[{"name":"C","amount":0},{"name":"B","amount":0},{"name":"A","amount":0}],"transactions":[{"from":"C","to":"A","amount":30}]}`,
+			expectedBody: `{"updated_balances":[{"name":"C","amount":0.00},{"name":"B","amount":0.00},{"name":"A","amount":0.00}],"transactions":[{"from":"C","to":"A","amount":30.00}]}`,
+		},
+		{
+			name: "should convert balances into settlement currency",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"name":"A","amount":770,"currency":"THB"},{"name":"B","amount":-20,"currency":"EUR"}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize?currency=EUR", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated_balances":[{"name":"B","amount":0.00,"currency":"EUR"},{"name":"A","amount":0.00,"currency":"EUR"}],"transactions":[{"from":"B","to":"A","amount":20.00,"currency":"EUR"}],"currency":"EUR","rates":[{"from":"THB","to":"EUR","rate":"0.02597403"}]}`,
+		},
+		{
+			name: "should return error when mixed currencies",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"name":"A","amount":770,"currency":"THB"},{"name":"B","amount":-20,"currency":"EUR"}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:bill-splitter:problem:mixed_currencies","title":"Bad Request","status":400,"detail":"amounts have different currencies, a settlement currency is required: THB and EUR","instance":"/transaction/minimize","code":"mixed_currencies","request_id":"test-request"}`,
+		},
+		{
+			name: "should split expenses and return balances",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"paid_by":[{"name":"A","amount":100}],"total":100,"split":"percentage","participants":[{"name":"B","percent":60},{"name":"C","percent":40}]}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/expense/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"A","amount":100.00},{"name":"B","amount":-60.00},{"name":"C","amount":-40.00}]`,
+		},
+		{
+			name: "should return error when expense split is invalid",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"paid_by":[{"name":"A","amount":100}],"total":100,"split":"percentage","participants":[{"name":"B","percent":60}]}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/expense/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"type":"urn:bill-splitter:problem:invalid_split","title":"Unprocessable Entity","status":422,"detail":"expense 0: invalid split: percentages sum to 60.00, expected 100.00","instance":"/expense/calculate","code":"invalid_split","request_id":"test-request"}`,
+		},
+		{
+			name: "should split receipt and return shares and balances",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`{"paid_by":[{"name":"A","amount":66}],"items":[{"amount":40,"consumers":["A"]},{"amount":20,"consumers":["B"]}],"tax":6}`))
+				r, _ := http.NewRequest("POST", baseUrl+"/receipt/calculate", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"shares":[{"name":"A","subtotal":40.00,"tax":4.00,"tip":0.00,"service_charge":0.00,"total":44.00},{"name":"B","subtotal":20.00,"tax":2.00,"tip":0.00,"service_charge":0.00,"total":22.00}],"balances":[{"name":"A","amount":22.00},{"name":"B","amount":-22.00}]}`,
 		},
 		{
 			name: "should return error when no content type",
@@ -57,8 +207,19 @@ func Test_Server_Integration(t *testing.T) {
 				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize", b)
 				return r
 			},
-			expectedCode: http.StatusBadRequest,
-			expectedBody: "Invalid `Content-Type` header. Expected `application/json`",
+			expectedCode: http.StatusUnsupportedMediaType,
+			expectedBody: `{"type":"urn:bill-splitter:problem:unsupported_media_type","title":"Unsupported Media Type","status":415,"detail":"Invalid ` + "`Content-Type`" + ` header. Expected ` + "`application/json`" + ` or ` + "`text/csv`" + `","instance":"/transaction/minimize","code":"unsupported_media_type","request_id":"test-request"}`,
+		},
+		{
+			name: "should reject body bigger than limit",
+			makeRequest: func() *http.Request {
+				b := bytes.NewBuffer([]byte(`[{"name":"A","amount":"` + strings.Repeat("0", 1<<20) + `1"}]`))
+				r, _ := http.NewRequest("POST", baseUrl+"/transaction/minimize", b)
+				r.Header = map[string][]string{"Content-Type": {"application/json"}}
+				return r
+			},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: `{"type":"urn:bill-splitter:problem:payload_too_large","title":"Request Entity Too Large","status":413,"detail":"request body is larger than 1048576 bytes","instance":"/transaction/minimize","code":"payload_too_large","request_id":"test-request"}`,
 		},
 		{
 			name: "should return error when no body",
@@ -68,8 +229,8 @@ func Test_Server_Integration(t *testing.T) {
 				r.Header = map[string][]string{"Content-Type": {"application/json"}}
 				return r
 			},
-			expectedCode: http.StatusInternalServerError,
-			expectedBody: "failed to read request body: EOF",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"urn:bill-splitter:problem:malformed_body","title":"Bad Request","status":400,"detail":"failed to read request body: EOF","instance":"/transaction/minimize","code":"malformed_body","request_id":"test-request"}`,
 		},
 	}
 
@@ -87,6 +248,7 @@ func Test_Server_Integration(t *testing.T) {
 		t.Run(s.name, func(t *testing.T) {
 
 			req := s.makeRequest()
+			req.Header.Set(RequestIDHeader, "test-request")
 
 			// default client is enough for testing
 			response, err := http.DefaultClient.Do(req)
@@ -96,6 +258,9 @@ func Test_Server_Integration(t *testing.T) {
 
 			actualCode, actualBody := extractCodeAndBody(response)
 
+			if requestID := response.Header.Get(RequestIDHeader); requestID != "test-request" {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", "test-request", requestID)
+			}
 			if s.expectedCode != actualCode {
 				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedCode, actualCode)
 			}
@@ -108,11 +273,84 @@ func Test_Server_Integration(t *testing.T) {
 	}
 }
 
-func setup() *HttpServer {
+func Test_Server_Run_Fails_To_Listen(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	defer listener.Close()
+
+	cfg := config.Default().Server
+	cfg.Addr = listener.Addr().String()
 	accService := accounting.NewService()
-	s := NewServer(accService, accService)
+	s := NewServer(cfg, accService, accService, accService, accService)
+
+	err = s.Run()
+
+	if err == nil || !strings.Contains(err.Error(), "address already in use") {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "address already in use", err)
+	}
+}
+
+func Test_Server_Shutdown_Is_Bounded(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.Addr = "127.0.0.1:8001"
+	cfg.ShutdownTimeout = config.Duration(50 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	s := &HttpServer{
+		server: &http.Server{Addr: cfg.Addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})},
+		shutdownTimeout: time.Duration(cfg.ShutdownTimeout),
+		osSigChan:       make(chan os.Signal, 1),
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- s.Run()
+	}()
+	waitListening(cfg.Addr)
 	go func() {
-		s.Run()
+		_, _ = http.Get("http://" + cfg.Addr)
 	}()
+	<-started
+
+	begin := time.Now()
+	s.Close()
+	err := <-result
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("shutdown took %s, expected to be bounded by the timeout", elapsed)
+	}
+}
+
+func setup() *HttpServer {
+	rates, _ := accounting.NewStaticRates("EUR", map[string]string{"THB": "38.5"})
+	accService := accounting.NewService(accounting.WithRateProvider(rates))
+	s := NewServer(config.Default().Server, accService, accService, accService, accService)
+	go func() {
+		if err := s.Run(); err != nil {
+			log.Printf("test server failed: %+v", err)
+		}
+	}()
+	waitListening(":8000")
 	return s
 }
+
+// waitListening waits the server to accept connections, avoiding races with the first request
+func waitListening(addr string) {
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,76 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		format        string
+		log           func(logger *slog.Logger)
+		expected      string
+		expectedError string
+	}{
+		{
+			name:   "should write json with request id from context",
+			format: FormatJSON,
+			log: func(logger *slog.Logger) {
+				logger.InfoContext(WithRequestID(context.Background(), "abc"), "hello", "n", 1)
+			},
+			expected: `"msg":"hello","n":1,"request_id":"abc"}`,
+		},
+		{
+			name:   "should write text keeping attributes and groups",
+			format: FormatText,
+			log: func(logger *slog.Logger) {
+				logger.With("component", "test").WithGroup("g").InfoContext(WithRequestID(context.Background(), "abc"), "hello")
+			},
+			expected: `msg=hello component=test g.request_id=abc`,
+		},
+		{
+			name:   "should not write request id when absent",
+			format: FormatText,
+			log: func(logger *slog.Logger) {
+				logger.Info("hello")
+			},
+			expected: `msg=hello` + "\n",
+		},
+		{
+			name:   "should filter below level",
+			format: FormatText,
+			log: func(logger *slog.Logger) {
+				logger.Debug("hidden")
+			},
+			expected: "",
+		},
+		{
+			name:          "should reject unknown format",
+			format:        "xml",
+			expectedError: `unknown log format "xml"`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, s.format, slog.LevelInfo)
+
+			if s.expectedError != "" {
+				if err == nil || err.Error() != s.expectedError {
+					t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expectedError, err)
+				}
+				return
+			}
+			s.log(logger)
+
+			if !strings.Contains(buf.String(), s.expected) || (s.expected == "" && buf.Len() > 0) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, buf.String())
+			}
+		})
+	}
+}
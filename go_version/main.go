@@ -1,12 +1,15 @@
 package main
 
 import (
-	"bill-splitter/accounting"
-	"bill-splitter/httpx"
+	"bill-splitter/cli"
+	"os"
 )
 
 func main() {
-	accService := accounting.NewService()
-	s := httpx.NewServer(accService, accService)
-	s.Run()
+	os.Exit(cli.Run(cli.Env{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Getenv: os.Getenv,
+	}, os.Args[1:]))
 }
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// HTTPMetrics records requests served by route and status
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+}
+
+func NewHTTPMetrics(r *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: r.NewCounter(
+			"bill_splitter_http_requests_total",
+			"Number of HTTP requests served.",
+			"method", "route", "status",
+		),
+		duration: r.NewHistogram(
+			"bill_splitter_http_request_duration_seconds",
+			"Time spent serving HTTP requests.",
+			DefBuckets, "method", "route", "status",
+		),
+	}
+}
+
+// ObserveRequest records a served request, route must be the matched pattern and not the path to keep few series
+func (hm *HTTPMetrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	hm.requests.Inc(method, route, code)
+	hm.duration.Observe(duration.Seconds(), method, route, code)
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bill-splitter/accounting"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Registry_Write_To(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		record   func(r *Registry)
+		expected string
+	}{
+		{
+			name: "should write counters sorted by labels",
+			record: func(r *Registry) {
+				c := r.NewCounter("requests_total", "Number of requests.", "route", "status")
+				c.Inc("/b", "200")
+				c.Inc("/a", "404")
+				c.Add(2, "/a", "404")
+				c.Add(-1, "/a", "404")
+			},
+			expected: `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{route="/a",status="404"} 3
+requests_total{route="/b",status="200"} 1
+`,
+		},
+		{
+			name: "should write cumulative histogram buckets",
+			record: func(r *Registry) {
+				h := r.NewHistogram("size", "Size of things.", []float64{10, 1, 5})
+				h.Observe(1)
+				h.Observe(3)
+				h.Observe(10)
+				h.Observe(20)
+			},
+			expected: `# HELP size Size of things.
+# TYPE size histogram
+size_bucket{le="1"} 1
+size_bucket{le="5"} 2
+size_bucket{le="10"} 3
+size_bucket{le="+Inf"} 4
+size_sum 34
+size_count 4
+`,
+		},
+		{
+			name: "should read gauges and counters on write",
+			record: func(r *Registry) {
+				r.NewGaugeFunc("temperature", "Current temperature.", func() float64 { return 21.5 })
+				r.NewCounterFunc("ticks_total", "Number of ticks.", func() float64 { return 3 })
+			},
+			expected: `# HELP temperature Current temperature.
+# TYPE temperature gauge
+temperature 21.5
+# HELP ticks_total Number of ticks.
+# TYPE ticks_total counter
+ticks_total 3
+`,
+		},
+		{
+			name: "should escape help and label values",
+			record: func(r *Registry) {
+				r.NewCounter("escaped_total", "Back\\slash\nnew line.", "value").Inc("say \"hi\"\n")
+			},
+			expected: `# HELP escaped_total Back\\slash\nnew line.
+# TYPE escaped_total counter
+escaped_total{value="say \"hi\"\n"} 1
+`,
+		},
+		{
+			name: "should write service metrics",
+			record: func(r *Registry) {
+				NewServiceMetrics(r).ObserveMinimize(accounting.MinimizeObservation{
+					GroupSize:    3,
+					Transactions: 2,
+					Objective:    accounting.ObjectiveCount,
+					Duration:     2 * time.Millisecond,
+				})
+			},
+			expected: `bill_splitter_minimize_transactions_bucket{objective="count",le="2"} 1`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			r := NewRegistry()
+			s.record(r)
+
+			var actual strings.Builder
+			if _, err := r.WriteTo(&actual); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if !strings.Contains(actual.String(), s.expected) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", s.expected, actual.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	rtmetrics "runtime/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userHZ is the unit of the CPU times in /proc, fixed at 100 on Linux
+const userHZ = 100
+
+var startTime = time.Now()
+
+// RegisterRuntime registers Go runtime and process metrics, named as the official Prometheus client names them
+// so the usual dashboards work. Process metrics other than the start time are only read from /proc on Linux
+func RegisterRuntime(r *Registry) {
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	r.NewGaugeFunc("go_threads", "Number of OS threads created.", func() float64 {
+		n, _ := runtime.ThreadCreateProfile(nil)
+		return float64(n)
+	})
+	for _, m := range []struct{ name, help, sample string }{
+		{"go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", "/memory/classes/heap/objects:bytes"},
+		{"go_memstats_heap_objects", "Number of allocated objects.", "/gc/heap/objects:objects"},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from system.", "/memory/classes/total:bytes"},
+	} {
+		r.NewGaugeFunc(m.name, m.help, func() float64 {
+			return readRuntime(m.sample)
+		})
+	}
+	r.NewCounterFunc("go_gc_cycles_total", "Number of completed GC cycles.", func() float64 {
+		return readRuntime("/gc/cycles/total:gc-cycles")
+	})
+
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return float64(startTime.UnixNano()) / 1e9
+	})
+	if _, err := procStat(); err != nil {
+		return
+	}
+	r.NewCounterFunc("process_cpu_seconds_total", "Total user and system CPU time spent in seconds.", func() float64 {
+		stat, _ := procStat()
+		return float64(stat.utime+stat.stime) / userHZ
+	})
+	r.NewGaugeFunc("process_resident_memory_bytes", "Resident memory size in bytes.", func() float64 {
+		stat, _ := procStat()
+		return float64(stat.rss * int64(os.Getpagesize()))
+	})
+	r.NewGaugeFunc("process_virtual_memory_bytes", "Virtual memory size in bytes.", func() float64 {
+		stat, _ := procStat()
+		return float64(stat.vsize)
+	})
+	r.NewGaugeFunc("process_open_fds", "Number of open file descriptors.", func() float64 {
+		fds, _ := os.ReadDir("/proc/self/fd")
+		return float64(len(fds))
+	})
+	r.NewGaugeFunc("process_max_fds", "Maximum number of open file descriptors.", procMaxFDs)
+}
+
+// readRuntime reads a runtime/metrics sample as a float, 0 when the runtime does not support it
+func readRuntime(name string) float64 {
+	sample := []rtmetrics.Sample{{Name: name}}
+	rtmetrics.Read(sample)
+	switch sample[0].Value.Kind() {
+	case rtmetrics.KindUint64:
+		return float64(sample[0].Value.Uint64())
+	case rtmetrics.KindFloat64:
+		return sample[0].Value.Float64()
+	}
+	return 0
+}
+
+type stat struct {
+	utime, stime int64
+	vsize, rss   int64
+}
+
+// procStat reads the CPU times and memory sizes of the process from /proc/self/stat
+func procStat() (stat, error) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return stat{}, err
+	}
+	// the command name is in parentheses and may hold spaces, fields are counted after it
+	fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+	if len(fields) < 22 {
+		return stat{}, os.ErrInvalid
+	}
+	field := func(i int) int64 {
+		// fields start at the third one of the file, the state
+		v, _ := strconv.ParseInt(fields[i-3], 10, 64)
+		return v
+	}
+	return stat{utime: field(14), stime: field(15), vsize: field(23), rss: field(24)}, nil
+}
+
+// procMaxFDs reads the soft limit of open files from /proc/self/limits
+func procMaxFDs() float64 {
+	data, err := os.ReadFile("/proc/self/limits")
+	if err != nil {
+		return 0
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Max open files"); ok {
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				v, _ := strconv.ParseFloat(fields[0], 64)
+				return v
+			}
+		}
+	}
+	return 0
+}
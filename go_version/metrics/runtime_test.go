@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_Register_Runtime(t *testing.T) {
+	r := NewRegistry()
+	RegisterRuntime(r)
+
+	var out strings.Builder
+	if _, err := r.WriteTo(&out); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := []string{"go_goroutines ", "go_threads ", "go_memstats_heap_alloc_bytes ", "go_gc_cycles_total ", "process_start_time_seconds "}
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		expected = append(expected, "process_cpu_seconds_total ", "process_resident_memory_bytes ", "process_open_fds ", "process_max_fds ")
+	}
+	for _, name := range expected {
+		if !strings.Contains(out.String(), "\n"+name) {
+			t.Errorf("\nExpected:	%+v\nGot:		%+v", name, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "process_resident_memory_bytes 0\n") {
+		t.Errorf("expected resident memory to be read, got %s", out.String())
+	}
+}
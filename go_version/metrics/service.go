@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"bill-splitter/accounting"
+)
+
+// ServiceMetrics records the accounting.Service telemetry
+type ServiceMetrics struct {
+	groupSize    *Histogram
+	transactions *Histogram
+	duration     *Histogram
+}
+
+var sizeBuckets = []float64{2, 4, 8, 16, 32, 64, 128, 256, 512, 1024}
+
+func NewServiceMetrics(r *Registry) *ServiceMetrics {
+	return &ServiceMetrics{
+		groupSize: r.NewHistogram(
+			"bill_splitter_minimize_group_size",
+			"Number of balances settled by each minimization.",
+			sizeBuckets, "objective",
+		),
+		transactions: r.NewHistogram(
+			"bill_splitter_minimize_transactions",
+			"Number of transactions produced by each minimization.",
+			sizeBuckets, "objective",
+		),
+		duration: r.NewHistogram(
+			"bill_splitter_minimize_duration_seconds",
+			"Time spent settling balances.",
+			[]float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}, "objective",
+		),
+	}
+}
+
+func (sm *ServiceMetrics) ObserveMinimize(o accounting.MinimizeObservation) {
+	objective := string(o.Objective)
+	sm.groupSize.Observe(float64(o.GroupSize), objective)
+	sm.transactions.Observe(float64(o.Transactions), objective)
+	sm.duration.Observe(o.Duration.Seconds(), objective)
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bill-splitter/accounting"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	groupExt       = ".json"
+	ledgerExt      = ".jsonl"
+	settlementsExt = ".settlements.jsonl"
+)
+
+// FileLedger is an accounting.LedgerRepository embedded on disk, under its directory there is
+// one JSON file per group and append-only JSON lines files per group ledger, for transaction revisions and settlements
+type FileLedger struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileLedger opens (creating if needed) a ledger stored under dir
+func NewFileLedger(dir string) (*FileLedger, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	return &FileLedger{dir: dir}, nil
+}
+
+// CheckHealth verifies the ledger directory still exists and is writable
+func (fl *FileLedger) CheckHealth(ctx context.Context) error {
+	return CheckWritable(ctx, fl.dir)
+}
+
+// CheckWritable verifies a ledger directory still exists and is writable, leaving nothing behind
+func CheckWritable(ctx context.Context, dir string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("ledger directory is not writable: %w", err)
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
+}
+
+func (fl *FileLedger) groupPath(id string) string {
+	return filepath.Join(fl.dir, id+groupExt)
+}
+
+func (fl *FileLedger) ledgerPath(id string) string {
+	return filepath.Join(fl.dir, id+ledgerExt)
+}
+
+func (fl *FileLedger) settlementsPath(id string) string {
+	return filepath.Join(fl.dir, id+settlementsExt)
+}
+
+func (fl *FileLedger) SaveGroup(group accounting.Group) error {
+	if err := validID(group.ID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		return fmt.Errorf("failed to encode group: %w", err)
+	}
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	return WriteFileAtomic(fl.groupPath(group.ID), data)
+}
+
+func (fl *FileLedger) Group(id string) (accounting.Group, error) {
+	if validID(id) != nil {
+		return accounting.Group{}, accounting.ErrGroupNotFound
+	}
+
+	fl.mu.RLock()
+	defer fl.mu.RUnlock()
+	return fl.readGroup(fl.groupPath(id))
+}
+
+func (fl *FileLedger) Groups() ([]accounting.Group, error) {
+	fl.mu.RLock()
+	defer fl.mu.RUnlock()
+
+	paths, err := filepath.Glob(filepath.Join(fl.dir, "*"+groupExt))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]accounting.Group, 0, len(paths))
+	for _, p := range paths {
+		g, err := fl.readGroup(p)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	accounting.SortGroups(groups)
+	return groups, nil
+}
+
+func (fl *FileLedger) AppendRevisions(groupID string, revisions ...accounting.Revision) error {
+	if _, err := fl.Group(groupID); err != nil {
+		return err
+	}
+	return appendLines(fl, fl.ledgerPath(groupID), revisions)
+}
+
+// Revisions also reads ledgers written before transactions had revisions, each line being a transaction without id
+func (fl *FileLedger) Revisions(groupID string) ([]accounting.Revision, error) {
+	if _, err := fl.Group(groupID); err != nil {
+		return nil, err
+	}
+	return readLines[accounting.Revision](fl, fl.ledgerPath(groupID), groupID)
+}
+
+func (fl *FileLedger) AppendSettlements(groupID string, settlements ...accounting.Settlement) error {
+	if _, err := fl.Group(groupID); err != nil {
+		return err
+	}
+	return appendLines(fl, fl.settlementsPath(groupID), settlements)
+}
+
+func (fl *FileLedger) Settlements(groupID string) (accounting.Settlements, error) {
+	if _, err := fl.Group(groupID); err != nil {
+		return nil, err
+	}
+	return readLines[accounting.Settlement](fl, fl.settlementsPath(groupID), groupID)
+}
+
+// appendLines writes every item as a JSON line at the end of the file, synced before returning
+func appendLines[T any](fl *FileLedger, path string, items []T) error {
+	var buf []byte
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode %T: %w", item, err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open ledger: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append to ledger: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync ledger: %w", err)
+	}
+	return f.Close()
+}
+
+// readLines decodes every JSON line of the file, a missing file is an empty ledger
+func readLines[T any](fl *FileLedger, path, groupID string) ([]T, error) {
+	fl.mu.RLock()
+	defer fl.mu.RUnlock()
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []T{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer f.Close()
+
+	items := []T{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var item T
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("corrupted ledger %s at line %d: %w", groupID, line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+	return items, nil
+}
+
+func (fl *FileLedger) readGroup(path string) (accounting.Group, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return accounting.Group{}, accounting.ErrGroupNotFound
+	}
+	if err != nil {
+		return accounting.Group{}, fmt.Errorf("failed to read group: %w", err)
+	}
+
+	var g accounting.Group
+	if err := json.Unmarshal(data, &g); err != nil {
+		return accounting.Group{}, fmt.Errorf("corrupted group file %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// validID ensures an id can safely be used as a file name
+func validID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("invalid id %q", id)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes to a temporary file and renames it, so readers never see a partial file
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bill-splitter/accounting"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_File_Ledger(t *testing.T) {
+	dir := t.TempDir()
+	ledger, err := NewFileLedger(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	group := accounting.Group{
+		ID:        "g1",
+		Name:      "Bangkok",
+		Currency:  "THB",
+		Members:   []accounting.Member{{Name: "A"}, {Name: "B"}},
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := ledger.SaveGroup(group); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	revisions := []accounting.Revision{
+		{
+			Transaction: accounting.Transaction{ID: "t1", Version: 1, From: "A", To: "B", Amount: accounting.MustParseMoney("40"), Currency: "THB"},
+			Action:      accounting.ActionCreated,
+			Actor:       "A",
+			At:          group.CreatedAt,
+		},
+		{
+			Transaction: accounting.Transaction{ID: "t1", Version: 2, From: "B", To: "A", Amount: accounting.MustParseMoney("0.10"), Currency: "THB"},
+			Action:      accounting.ActionUpdated,
+			At:          group.CreatedAt,
+		},
+	}
+	if err := ledger.AppendRevisions("g1", revisions[0]); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := ledger.AppendRevisions("g1", revisions[1]); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// a new instance reads what was persisted
+	reopened, err := NewFileLedger(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	actualGroup, err := reopened.Group("g1")
+	if err != nil || !reflect.DeepEqual(group, actualGroup) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", group, actualGroup, err)
+	}
+
+	actualGroups, err := reopened.Groups()
+	if err != nil || !reflect.DeepEqual([]accounting.Group{group}, actualGroups) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", []accounting.Group{group}, actualGroups, err)
+	}
+
+	actualRevisions, err := reopened.Revisions("g1")
+	if err != nil || !reflect.DeepEqual(revisions, actualRevisions) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", revisions, actualRevisions, err)
+	}
+
+	settlements := accounting.Settlements{
+		{ID: "s1", From: "B", To: "A", Amount: accounting.MustParseMoney("20"), Currency: "THB", PaidAt: group.CreatedAt},
+	}
+	if err := ledger.AppendSettlements("g1", settlements...); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	actualSettlements, err := reopened.Settlements("g1")
+	if err != nil || !reflect.DeepEqual(settlements, actualSettlements) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", settlements, actualSettlements, err)
+	}
+
+	voided := settlements[0]
+	voided.VoidedAt = group.CreatedAt.Add(time.Hour)
+	if err := reopened.AppendSettlements("g1", voided); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	actualSettlements, err = ledger.Settlements("g1")
+	expectedSettlements := append(settlements, voided)
+	if err != nil || !reflect.DeepEqual(expectedSettlements, actualSettlements) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", expectedSettlements, actualSettlements, err)
+	}
+
+	actualGroups, err = reopened.Groups()
+	if err != nil || len(actualGroups) != 1 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", []accounting.Group{group}, actualGroups, err)
+	}
+}
+
+func Test_File_Ledger_Without_Revisions(t *testing.T) {
+	dir := t.TempDir()
+	ledger, err := NewFileLedger(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if err := ledger.SaveGroup(accounting.Group{ID: "g1", Name: "Bangkok"}); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// ledgers written before revisions have one transaction per line
+	line := `{"from":"A","to":"B","amount":"40","currency":"THB"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "g1"+ledgerExt), []byte(line), 0o644); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := []accounting.Revision{
+		{Transaction: accounting.Transaction{From: "A", To: "B", Amount: accounting.MustParseMoney("40"), Currency: "THB"}},
+	}
+	actual, err := ledger.Revisions("g1")
+	if err != nil || !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v (%+v)", expected, actual, err)
+	}
+}
+
+func Test_File_Ledger_Not_Found(t *testing.T) {
+	ledger, err := NewFileLedger(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	scenarios := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "when reading group",
+			call: func() error {
+				_, err := ledger.Group("missing")
+				return err
+			},
+		},
+		{
+			name: "when reading revisions",
+			call: func() error {
+				_, err := ledger.Revisions("missing")
+				return err
+			},
+		},
+		{
+			name: "when appending revisions",
+			call: func() error {
+				return ledger.AppendRevisions("missing", accounting.Revision{Transaction: accounting.Transaction{From: "A", To: "B"}})
+			},
+		},
+		{
+			name: "when id escapes the directory",
+			call: func() error {
+				_, err := ledger.Group("../missing")
+				return err
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if err := s.call(); !errors.Is(err, accounting.ErrGroupNotFound) {
+				t.Errorf("\nExpected:	%+v\nGot:		%+v", accounting.ErrGroupNotFound, err)
+			}
+		})
+	}
+}
+
+func Test_File_Ledger_Check_Health(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ledger")
+	ledger, err := NewFileLedger(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	if err := ledger.CheckHealth(context.Background()); err != nil {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", nil, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", 0, len(entries))
+	}
+
+	_ = os.RemoveAll(dir)
+	if err := ledger.CheckHealth(context.Background()); err == nil {
+		t.Errorf("\nExpected:	%+v\nGot:		%+v", "ledger directory is not writable", err)
+	}
+}